@@ -1,15 +1,20 @@
 package config_load
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"math/rand/v2"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	_ "github.com/spf13/viper/remote"
 )
 
 type (
@@ -19,9 +24,34 @@ type (
 		consulURL             string
 		envPrefix             string
 		configFileName        string
+		configOverlays        []string
 		remoteMaxAttempt      int
+		backoffInitial        time.Duration
+		backoffMax            time.Duration
+		backoffJitter         float64
 		tagName               string
 		configFileSearchPaths []string
+		watchInterval         time.Duration
+		errorHandler          func(err error)
+		secretResolvers       map[string]SecretResolver
+		configFileOptional    bool
+		flags                 *pflag.FlagSet // flags generated by BindFlags
+		decodeHooks           []mapstructure.DecodeHookFunc
+
+		// mu serializes reads of the sources and decoding, so a reload never
+		// races with Load or another reload on the shared viper instance.
+		mu            sync.Mutex
+		source        Source
+		fromRemote    bool
+		remotePayload []byte
+		secretKeys    map[string]struct{}
+		fields        []field              // leaves of the struct passed to the last Load
+		boundEnv      map[string]struct{}  // keys already passed to BindEnv
+		configFiles   []string             // files read in file mode, base first
+		keyOrigins    map[string]keyOrigin // key -> file or snapshot that last set it
+		snapshotPath  string
+		snapshotTime  time.Time // write time of the snapshot used by the last load
+		usedSnapshot  bool
 	}
 	Option func(*ViperLoader)
 )
@@ -29,6 +59,7 @@ type (
 var (
 	ErrInvalidInput       = fmt.Errorf("cfg must be a pointer to struct and initialized")
 	ErrConfigFileNotFound = fmt.Errorf("config file not found")
+	ErrValidation         = fmt.Errorf("config validation failed")
 )
 
 // WithConfigFileSearchPaths will add paths to where the loader will search the configuration files
@@ -40,6 +71,8 @@ func WithConfigFileSearchPaths(paths ...string) Option {
 	}
 }
 
+// WithLoadFromConsulMaxAttempt sets how many times the remote source is tried before
+// falling back (default 5). Despite the name it applies to any Source.
 func WithLoadFromConsulMaxAttempt(n int) Option {
 	return func(v *ViperLoader) {
 		if n > 0 {
@@ -48,28 +81,96 @@ func WithLoadFromConsulMaxAttempt(n int) Option {
 	}
 }
 
+// WithRemoteBackoff sets the exponential backoff between remote attempts: the first
+// retry waits initial, each following one doubles up to max (defaults 500ms and 5s).
+func WithRemoteBackoff(initial, max time.Duration) Option {
+	return func(v *ViperLoader) {
+		if initial > 0 {
+			v.backoffInitial = initial
+		}
+		if max > 0 {
+			v.backoffMax = max
+		}
+	}
+}
+
+// WithRemoteBackoffJitter randomizes each backoff delay by up to ±fraction of its
+// value (default 0.2) so replicas booting together do not retry in lockstep.
+// 0 disables jitter.
+func WithRemoteBackoffJitter(fraction float64) Option {
+	return func(v *ViperLoader) {
+		if fraction >= 0 && fraction <= 1 {
+			v.backoffJitter = fraction
+		}
+	}
+}
+
 func WithConfigFileName(fileName string) Option {
 	return func(v *ViperLoader) {
 		v.configFileName = fileName
 	}
 }
 
+// WithConfigOverlays sets an ordered list of config file names (without extension) that
+// are deep-merged on top of each other. The first name is the base file and replaces
+// WithConfigFileName; it must exist. Later overlays are optional and skipped when no
+// file matches. Names may reference env vars, e.g. "config.${APP_ENV}"; an overlay
+// whose variable is empty is skipped.
+//
+//	WithConfigOverlays("config", "config.${APP_ENV}", "config.local")
+func WithConfigOverlays(names ...string) Option {
+	return func(v *ViperLoader) {
+		if len(names) == 0 {
+			return
+		}
+		v.configFileName = names[0]
+		v.configOverlays = append([]string(nil), names[1:]...)
+	}
+}
+
 func WithStructTagName(name string) Option {
 	return func(v *ViperLoader) {
 		v.tagName = name
 	}
 }
 
+// WithWatchInterval sets the poll/retry interval of the Consul source built from the
+// New arguments (default 30s). Sources passed to WithSource use their own
+// WithSourcePollInterval; files are watched with fsnotify.
+func WithWatchInterval(d time.Duration) Option {
+	return func(v *ViperLoader) {
+		if d > 0 {
+			v.watchInterval = d
+		}
+	}
+}
+
+// WithErrorHandler installs a callback for errors that have no caller to return to,
+// such as a failed reload during Watch. The previously loaded config stays active.
+func WithErrorHandler(fn func(err error)) Option {
+	return func(v *ViperLoader) {
+		v.errorHandler = fn
+	}
+}
+
 func New(envPrefix, consulKey, consulURL string, opts ...Option) *ViperLoader {
 	v := &ViperLoader{
 		Viper:                 viper.New(),
 		configFileName:        "config",
 		remoteMaxAttempt:      5,
+		backoffInitial:        500 * time.Millisecond,
+		backoffMax:            5 * time.Second,
+		backoffJitter:         0.2,
 		tagName:               "json",
 		envPrefix:             envPrefix,
 		consulKey:             consulKey,
 		consulURL:             consulURL,
 		configFileSearchPaths: []string{"."},
+		watchInterval:         30 * time.Second,
+		secretResolvers: map[string]SecretResolver{
+			"file": fileSecretResolver{},
+			"env":  envSecretResolver{},
+		},
 	}
 	for _, opt := range opts {
 		opt(v)
@@ -77,29 +178,94 @@ func New(envPrefix, consulKey, consulURL string, opts ...Option) *ViperLoader {
 	return v
 }
 
-func (v *ViperLoader) Load(cfg interface{}) (err error) {
-	decOption := func(dc *mapstructure.DecoderConfig) {
-		dc.TagName = v.tagName
-	}
-	rv := reflect.ValueOf(cfg)
-	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+func (v *ViperLoader) Load(cfg interface{}) error {
+	return v.LoadContext(context.Background(), cfg)
+}
+
+// LoadContext is Load bounded by ctx: remote retries stop as soon as ctx is done and
+// the *RemoteLoadError is returned instead of falling back to file and env. When the
+// remote source fails for any other reason the loader falls back as usual and
+// reports the *RemoteLoadError to WithErrorHandler.
+func (v *ViperLoader) LoadContext(ctx context.Context, cfg interface{}) error {
+	if !isStructPointer(cfg) {
 		return ErrInvalidInput
 	}
-	if v.consulURL != "" {
-		consulErr := v.loadFromConsul()
-		if consulErr == nil {
-			err = v.Unmarshal(cfg, decOption)
-			return
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fields = structFields(reflect.TypeOf(cfg).Elem(), v.tagName)
+	if err := v.registerDefaults(reflect.TypeOf(cfg).Elem()); err != nil {
+		return err
+	}
+	if err := v.bindChangedFlags(); err != nil {
+		return err
+	}
+	if err := v.read(ctx); err != nil {
+		return err
+	}
+	return v.decode(ctx, cfg)
+}
+
+// read populates the viper instance from the remote source, or from file and env when
+// no source is configured or it fails.
+func (v *ViperLoader) read(ctx context.Context) error {
+	if src := v.remoteSource(); src != nil {
+		remoteErr := v.loadFromRemote(ctx, src)
+		if remoteErr == nil {
+			v.fromRemote = true
+			return nil
+		}
+		if ctx.Err() != nil {
+			return remoteErr
 		}
-		log.Printf("consul load failed: %+v. Falling back to file and environment variables.\n", consulErr)
+		v.fromRemote = false
+		if err := v.loadFallback(); err != nil {
+			return errors.Join(remoteErr, err)
+		}
+		v.handleError(fmt.Errorf("config: falling back to file and environment variables: %w", remoteErr))
+		return nil
 	}
-	err = v.loadFromFileAndEnv()
-	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-		err = fmt.Errorf("%w: no '%s' file found on search paths", ErrConfigFileNotFound, v.configFileName)
-		return
+	v.fromRemote = false
+	return v.loadFallback()
+}
+
+// loadFallback reads file and env, with the last remote snapshot (if any) layered
+// between them: remote -> snapshot -> file -> env. A missing config file is only an
+// error when there is no snapshot to fall back on and WithConfigFileOptional is not set.
+func (v *ViperLoader) loadFallback() error {
+	err := v.loadFromFileAndEnv()
+	_, notFound := err.(viper.ConfigFileNotFoundError)
+	if err != nil && !notFound {
+		return err
+	}
+	usedSnapshot, snapErr := v.mergeSnapshot()
+	if snapErr != nil {
+		v.handleError(snapErr)
 	}
-	err = v.Unmarshal(cfg, decOption)
-	return
+	if notFound && !usedSnapshot && !v.configFileOptional {
+		return fmt.Errorf("%w: no '%s' file found on search paths", ErrConfigFileNotFound, v.configFileName)
+	}
+	return nil
+}
+
+// decode unmarshals the current viper state into cfg, expands secret references and
+// checks its `validate` tags.
+func (v *ViperLoader) decode(ctx context.Context, cfg interface{}) error {
+	err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = v.tagName
+		dc.DecodeHook = v.decodeHook()
+	})
+	if err != nil {
+		return err
+	}
+	if err := v.resolveSecrets(ctx, cfg); err != nil {
+		return err
+	}
+	return validate(cfg, v.tagName)
+}
+
+func isStructPointer(cfg interface{}) bool {
+	rv := reflect.ValueOf(cfg)
+	return rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct
 }
 
 func (v *ViperLoader) loadFromFileAndEnv() error {
@@ -110,28 +276,154 @@ func (v *ViperLoader) loadFromFileAndEnv() error {
 	v.SetEnvPrefix(v.envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
-	return v.ReadInConfig()
+	if err := v.bindEnvs(); err != nil {
+		return err
+	}
+	v.configFiles = nil
+	v.keyOrigins = make(map[string]keyOrigin)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	v.configFiles = []string{v.ConfigFileUsed()}
+	v.trackOrigins(v.ConfigFileUsed())
+	return v.mergeOverlays()
 }
 
-func (v *ViperLoader) loadFromConsul() error {
-	err := v.AddRemoteProvider("consul", v.consulURL, v.consulKey)
-	if err != nil {
-		return err
+// mergeOverlays merges every optional overlay found on the search paths over the
+// base file, in order, with viper's deep-map merge semantics.
+func (v *ViperLoader) mergeOverlays() error {
+	for _, name := range v.configOverlays {
+		name = os.ExpandEnv(name)
+		if name == "" || strings.HasSuffix(name, ".") {
+			continue
+		}
+		v.SetConfigName(name)
+		err := v.MergeInConfig()
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("merge overlay %s: %w", name, err)
+		}
+		v.configFiles = append(v.configFiles, v.ConfigFileUsed())
+		v.trackOrigins(v.ConfigFileUsed())
+	}
+	return nil
+}
+
+// keyOrigin is the layer and file that last set a key in file mode.
+type keyOrigin struct {
+	layer Layer
+	path  string
+}
+
+// trackOrigins records path as the origin of every key it sets. Files are tracked in
+// merge order, so the last file to set a key owns it.
+func (v *ViperLoader) trackOrigins(path string) {
+	tmp := viper.New()
+	tmp.SetConfigFile(path)
+	if err := tmp.ReadInConfig(); err != nil {
+		return
+	}
+	for _, key := range tmp.AllKeys() {
+		v.keyOrigins[key] = keyOrigin{layer: LayerFile, path: path}
 	}
+}
+
+// remoteSource returns the configured Source, building the Consul source from the
+// New arguments on first use. It is nil when no remote source is configured.
+func (v *ViperLoader) remoteSource() Source {
+	if v.source == nil && v.consulURL != "" {
+		v.source = NewConsulSource(v.consulURL, v.consulKey, WithSourcePollInterval(v.watchInterval))
+	}
+	return v.source
+}
 
-	v.SetConfigType("yaml")
-	stop := false
-	attempt := 0
-	for !stop {
-		err = v.ReadRemoteConfig()
-		attempt++
-		stop = err == nil || attempt >= v.remoteMaxAttempt
-		if !stop {
-			time.Sleep(500 * time.Millisecond)
+func (v *ViperLoader) loadFromRemote(ctx context.Context, src Source) error {
+	rerr := &RemoteLoadError{Source: src.Name()}
+	for attempt := 1; ; attempt++ {
+		payload, err := src.Fetch(ctx)
+		if err == nil {
+			err = v.applyRemote(src, payload)
+		}
+		if err == nil {
+			return nil
+		}
+		rerr.Attempts = append(rerr.Attempts, AttemptError{Attempt: attempt, Err: err})
+		if ctx.Err() != nil || attempt >= v.remoteMaxAttempt {
+			return rerr
+		}
+		timer := time.NewTimer(v.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			rerr.Attempts = append(rerr.Attempts, AttemptError{Attempt: attempt + 1, Err: ctx.Err()})
+			return rerr
+		case <-timer.C:
 		}
+	}
+}
 
+// clearKey sets the dotted key to nil in m, creating the maps along its path. Keys
+// below a value that is not a map are left alone; the value replaces them on merge.
+func clearKey(m map[string]any, key string) {
+	path := strings.Split(key, ".")
+	for _, k := range path[:len(path)-1] {
+		next, ok := m[k]
+		if !ok {
+			sub := make(map[string]any)
+			m[k] = sub
+			m = sub
+			continue
+		}
+		if m, ok = next.(map[string]any); !ok {
+			return
+		}
 	}
+	m[path[len(path)-1]] = nil
+}
 
-	log.Printf("Initializing remote config, consul endpoint: %s, consul key: %s, number of attempt: %d", v.consulURL, v.consulKey, attempt)
-	return err
+// backoff returns the delay after the given (1-based) failed attempt.
+func (v *ViperLoader) backoff(attempt int) time.Duration {
+	d := v.backoffInitial
+	for i := 1; i < attempt && d < v.backoffMax; i++ {
+		d *= 2
+	}
+	if d > v.backoffMax {
+		d = v.backoffMax
+	}
+	if v.backoffJitter > 0 {
+		d += time.Duration(float64(d) * v.backoffJitter * (2*rand.Float64() - 1))
+	}
+	return d
+}
+
+// applyRemote replaces the config layer with payload, so keys deleted at the source
+// disappear on reload instead of lingering from the previous read. The payload is
+// parsed on a separate instance, as for the snapshot: viper cannot unset a config
+// type, so setting the source's on v would make a later fallback parse the config
+// file in the source's format.
+func (v *ViperLoader) applyRemote(src Source, payload []byte) error {
+	tmp := viper.New()
+	tmp.SetConfigType(src.ConfigType())
+	if err := tmp.ReadConfig(bytes.NewReader(payload)); err != nil {
+		return err
+	}
+	// MergeConfigMap cannot delete keys, but a nil leaf reads as unset: clear every
+	// key the payload does not set so it falls through to the layers below.
+	settings := tmp.AllSettings()
+	for _, key := range v.AllKeys() {
+		if !tmp.IsSet(key) {
+			clearKey(settings, key)
+		}
+	}
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	v.remotePayload = payload
+	v.usedSnapshot = false
+	if err := v.writeSnapshot(payload); err != nil {
+		v.handleError(err)
+	}
+	return nil
 }
@@ -0,0 +1,146 @@
+package config_load
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// WithDecodeHook adds mapstructure decode hooks for custom types. They run before the
+// built-in hooks, so they can also override how a built-in type is decoded.
+//
+// The built-in hooks decode, from strings:
+//   - time.Duration ("30s") and ByteSize ("512MiB")
+//   - any type implementing encoding.TextUnmarshaler (netip.Prefix, netip.Addr,
+//     time.Time as RFC 3339, slog.Level, your own types)
+//   - *url.URL
+//   - slices from comma separated values ("a, b, c"), e.g. from env vars
+func WithDecodeHook(hooks ...mapstructure.DecodeHookFunc) Option {
+	return func(v *ViperLoader) {
+		v.decodeHooks = append(v.decodeHooks, hooks...)
+	}
+}
+
+// decodeHook composes the custom hooks with the built-in ones. It keeps viper's
+// defaults (durations and comma separated slices) so replacing them loses nothing.
+func (v *ViperLoader) decodeHook() mapstructure.DecodeHookFunc {
+	hooks := append([]mapstructure.DecodeHookFunc(nil), v.decodeHooks...)
+	hooks = append(hooks,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToURLHookFunc(),
+		stringToSliceHook(","),
+	)
+	return mapstructure.ComposeDecodeHookFunc(hooks...)
+}
+
+// stringToSliceHook splits a string into a slice on sep and trims the elements, so
+// APP_HOSTS="a, b" decodes like [a, b]. Byte slices are left alone.
+func stringToSliceHook(sep string) mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8 {
+			return data, nil
+		}
+		raw := strings.TrimSpace(data.(string))
+		if raw == "" {
+			return []string{}, nil
+		}
+		parts := strings.Split(raw, sep)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts, nil
+	}
+}
+
+// ByteSize is a size in bytes that decodes from "512MiB", "1.5GB" or a plain number.
+// Binary units (KiB, MiB, GiB, TiB) are powers of 1024, decimal units (KB, MB, GB,
+// TB) powers of 1000; units are case-insensitive.
+type ByteSize uint64
+
+const (
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+)
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// byteSizePattern matches the strings ParseByteSize accepts.
+const byteSizePattern = `^\s*[0-9.]+\s*([bB]|[kKmMgGtT][iI]?[bB])?\s*$`
+
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": float64(KiB),
+	"mib": float64(MiB),
+	"gib": float64(GiB),
+	"tib": float64(TiB),
+}
+
+// ParseByteSize parses s as described on ByteSize.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	mult, ok := byteUnits[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	size := n * mult
+	if size > math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	return ByteSize(size), nil
+}
+
+// UnmarshalText parses text with ParseByteSize. Empty text (e.g. APP_MAX_SIZE="")
+// decodes to 0, like an empty number would.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) == 0 {
+		*b = 0
+		return nil
+	}
+	n, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = n
+	return nil
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// String renders b in the largest binary unit that divides it exactly, e.g. "512MiB".
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		size ByteSize
+		name string
+	}{{TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}} {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
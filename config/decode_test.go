@@ -0,0 +1,195 @@
+package config_load
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
+)
+
+type decodeConfig struct {
+	Cache struct {
+		Size    ByteSize      `mapstructure:"size"`
+		MaxSize ByteSize      `mapstructure:"max_size" default:"1GiB"`
+		TTL     time.Duration `mapstructure:"ttl"`
+	} `mapstructure:"cache"`
+	Upstream *url.URL     `mapstructure:"upstream"`
+	Allow    netip.Prefix `mapstructure:"allow"`
+	Level    slog.Level   `mapstructure:"level"`
+	Hosts    []string     `mapstructure:"hosts"`
+	Ports    []int        `mapstructure:"ports"`
+	Color    color        `mapstructure:"color"`
+	Started  time.Time    `mapstructure:"started"`
+}
+
+// color is decoded by a custom hook in the tests.
+type color struct{ R, G, B uint8 }
+
+func hexColorHook(f, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String || t != reflect.TypeOf(color{}) {
+		return data, nil
+	}
+	var c color
+	if _, err := fmt.Sscanf(data.(string), "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func TestLoad_BuiltInDecodeHooks(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", `
+cache:
+  size: 512MiB
+  ttl: 90s
+upstream: https://api.example.com/v1
+allow: 10.0.0.0/8
+level: WARN
+ports: [80, 443]
+color: "#ff8000"
+started: 2026-01-02T15:04:05Z
+`)
+	t.Setenv("APP_HOSTS", "a.internal, b.internal")
+
+	loader := New("APP", "", "",
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithDecodeHook(mapstructure.DecodeHookFuncType(hexColorHook)),
+	)
+	var cfg decodeConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Cache.Size != 512*MiB || cfg.Cache.MaxSize != GiB || cfg.Cache.TTL != 90*time.Second {
+		t.Fatalf("unexpected cache: %+v", cfg.Cache)
+	}
+	if cfg.Upstream == nil || cfg.Upstream.Host != "api.example.com" || cfg.Upstream.Path != "/v1" {
+		t.Fatalf("unexpected upstream: %v", cfg.Upstream)
+	}
+	if cfg.Allow != netip.MustParsePrefix("10.0.0.0/8") || cfg.Level != slog.LevelWarn {
+		t.Fatalf("unexpected allow/level: %v %v", cfg.Allow, cfg.Level)
+	}
+	if strings.Join(cfg.Hosts, "|") != "a.internal|b.internal" {
+		t.Fatalf("expected env list split and trimmed, got %q", cfg.Hosts)
+	}
+	if !reflect.DeepEqual(cfg.Ports, []int{80, 443}) {
+		t.Fatalf("unexpected ports: %v", cfg.Ports)
+	}
+	if cfg.Color != (color{0xff, 0x80, 0x00}) {
+		t.Fatalf("custom hook not applied: %+v", cfg.Color)
+	}
+	if !cfg.Started.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected started: %v", cfg.Started)
+	}
+
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	for _, k := range exp.Keys {
+		if k.Key == "cache.size" && k.Value != "512MiB" {
+			t.Fatalf("expected byte size rendered as text, got %v", k.Value)
+		}
+	}
+}
+
+func TestLoad_DecodeHookErrorNamesField(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "cache:\n  size: 12 parsecs\n")
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg decodeConfig
+	err := loader.Load(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "cache.size") || !strings.Contains(err.Error(), "12 parsecs") {
+		t.Fatalf("expected error naming cache.size, got %v", err)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]ByteSize{
+		"1024":    1024,
+		"512MiB":  512 * MiB,
+		"1.5 GiB": 3 * GiB / 2,
+		"10kb":    10_000,
+		"2TB":     2_000_000_000_000,
+		"64 b":    64,
+	}
+	for in, want := range cases {
+		got, err := ParseByteSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "MiB", "1.2.3MB", "5 parsecs", "-1KiB"} {
+		if _, err := ParseByteSize(bad); err == nil {
+			t.Errorf("ParseByteSize(%q) should fail", bad)
+		}
+	}
+	var empty ByteSize = 1
+	if err := empty.UnmarshalText([]byte("")); err != nil || empty != 0 {
+		t.Errorf("empty text should decode to 0, got %d, %v", empty, err)
+	}
+	if s := (3 * GiB).String(); s != "3GiB" {
+		t.Errorf("expected 3GiB, got %s", s)
+	}
+	if s := ByteSize(1500).String(); s != "1500B" {
+		t.Errorf("expected 1500B, got %s", s)
+	}
+}
+
+func TestByteSize_UnsetWithBindFlags(t *testing.T) {
+	type cfgT struct {
+		Size    ByteSize `mapstructure:"size"`
+		MaxSize ByteSize `mapstructure:"max_size"`
+	}
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "{}\n")
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	fs := pflag.NewFlagSet("job", pflag.ContinueOnError)
+	var cfg cfgT
+	if err := loader.BindFlags(fs, &cfg); err != nil {
+		t.Fatalf("bind flags: %v", err)
+	}
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := loader.Load(&cfg); err != nil || cfg.Size != 0 || cfg.MaxSize != 0 {
+		t.Fatalf("unset byte sizes must load as 0, got %+v, %v", cfg, err)
+	}
+}
+
+func TestURL_IsALeaf(t *testing.T) {
+	type cfgT struct {
+		Endpoint *url.URL `mapstructure:"endpoint" validate:"required"`
+	}
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "{}\n")
+	newLoader := func() *ViperLoader {
+		return New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	}
+
+	var cfg cfgT
+	if err := newLoader().Load(&cfg); !errors.Is(err, ErrValidation) {
+		t.Fatalf("an unset required URL must fail validation, got %v", err)
+	}
+
+	t.Setenv("APP_ENDPOINT", "https://api.example.com/v1")
+	cfg = cfgT{}
+	if err := newLoader().Load(&cfg); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Endpoint == nil || cfg.Endpoint.String() != "https://api.example.com/v1" {
+		t.Fatalf("expected the URL from env, got %v", cfg.Endpoint)
+	}
+
+	if s := typeSchema(reflect.TypeOf(cfg.Endpoint), "mapstructure"); s["type"] != "string" || s["format"] != "uri" {
+		t.Fatalf("unexpected URL schema: %v", s)
+	}
+}
@@ -0,0 +1,79 @@
+package config_load
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// registerDefaults calls SetDefault for every field of t carrying a `default` tag.
+// Registering them on viper (instead of patching the struct afterwards) keeps them
+// at the bottom of the precedence chain and makes the keys known to AutomaticEnv.
+//
+// Slices take a comma separated list: `default:"a,b,c"`.
+func (v *ViperLoader) registerDefaults(t reflect.Type) error {
+	for _, f := range structFields(t, v.tagName) {
+		raw, ok := f.sf.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		val, err := parseDefault(f.sf.Type, raw)
+		if err != nil {
+			return fmt.Errorf("config: default for %s: %w", f.path, err)
+		}
+		v.SetDefault(f.path, val)
+	}
+	return nil
+}
+
+// parseDefault converts a tag literal to a value of t's kind. Kinds it does not know
+// are passed through as the raw string for the decoder to handle.
+func parseDefault(t reflect.Type, raw string) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return time.ParseDuration(raw)
+	}
+	if t == urlType {
+		if _, err := url.Parse(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return raw, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, t.Bits())
+	case reflect.Slice:
+		if raw == "" {
+			return []interface{}{}, nil
+		}
+		parts := strings.Split(raw, ",")
+		out := make([]interface{}, 0, len(parts))
+		for _, p := range parts {
+			el, err := parseDefault(t.Elem(), strings.TrimSpace(p))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, el)
+		}
+		return out, nil
+	}
+	return raw, nil
+}
@@ -0,0 +1,67 @@
+package config_load
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type defaultsConfig struct {
+	App struct {
+		Name    string        `mapstructure:"name" default:"svc"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Debug   bool          `mapstructure:"debug" default:"true"`
+	} `mapstructure:"app"`
+	DB struct {
+		Host string `mapstructure:"host" default:"localhost"`
+		Pool struct {
+			Max int `mapstructure:"max" default:"10"`
+		} `mapstructure:"pool"`
+	} `mapstructure:"db"`
+	Brokers []string `mapstructure:"brokers" default:"kafka-1:9092, kafka-2:9092"`
+	Ports   []int    `mapstructure:"ports" default:"80,443"`
+}
+
+func TestLoad_DefaultsFromTags(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", `
+app:
+  name: fromfile
+`)
+	t.Setenv("APP_DB_POOL_MAX", "25")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg defaultsConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "fromfile" {
+		t.Fatalf("file must win over default, got %q", cfg.App.Name)
+	}
+	if cfg.App.Timeout != 30*time.Second || !cfg.App.Debug {
+		t.Fatalf("scalar defaults not applied: %+v", cfg.App)
+	}
+	if cfg.DB.Host != "localhost" {
+		t.Fatalf("nested default not applied, got %q", cfg.DB.Host)
+	}
+	if cfg.DB.Pool.Max != 25 {
+		t.Fatalf("env must override a default-only key, got %d", cfg.DB.Pool.Max)
+	}
+	if !reflect.DeepEqual(cfg.Brokers, []string{"kafka-1:9092", "kafka-2:9092"}) {
+		t.Fatalf("slice default = %v", cfg.Brokers)
+	}
+	if !reflect.DeepEqual(cfg.Ports, []int{80, 443}) {
+		t.Fatalf("int slice default = %v", cfg.Ports)
+	}
+}
+
+func TestLoad_InvalidDefaultTag(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "a: 1\n")
+	var cfg struct {
+		Timeout time.Duration `json:"timeout" default:"soon"`
+	}
+	if err := New("APP", "", "", WithConfigFileSearchPaths(dir)).Load(&cfg); err == nil {
+		t.Fatal("expected error for unparsable default")
+	}
+}
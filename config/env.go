@@ -0,0 +1,56 @@
+package config_load
+
+import "strings"
+
+// WithConfigFileOptional lets Load run from env vars and defaults alone when no config
+// file is found on the search paths, instead of failing with ErrConfigFileNotFound.
+// A file that exists but cannot be parsed is still an error.
+func WithConfigFileOptional() Option {
+	return func(v *ViperLoader) {
+		v.configFileOptional = true
+	}
+}
+
+// bindEnvs binds every leaf of the target struct to its env var. AutomaticEnv alone
+// only consults the environment for keys viper already knows from the file or a
+// default, so a field missing from config.yaml would never be read from env.
+//
+// The variable is PREFIX_PATH_TO_KEY, plus the name in an `env:"NAME"` tag when
+// present (used as is, without the prefix).
+func (v *ViperLoader) bindEnvs() error {
+	if v.boundEnv == nil {
+		v.boundEnv = make(map[string]struct{})
+	}
+	for _, f := range v.fields {
+		if _, ok := v.boundEnv[f.path]; ok {
+			continue
+		}
+		input := []string{f.path}
+		if name := f.sf.Tag.Get("env"); name != "" {
+			input = append(input, name)
+		}
+		if err := v.BindEnv(input...); err != nil {
+			return err
+		}
+		v.boundEnv[f.path] = struct{}{}
+	}
+	return nil
+}
+
+// envVarsOf lists the variables viper reads for f, in the order it checks them.
+func (v *ViperLoader) envVarsOf(f field) []string {
+	names := []string{v.envVarName(f.path)}
+	if name := f.sf.Tag.Get("env"); name != "" && name != names[0] {
+		names = append(names, name)
+	}
+	return names
+}
+
+// envVarName returns the variable AutomaticEnv reads for key.
+func (v *ViperLoader) envVarName(key string) string {
+	name := strings.ReplaceAll(key, ".", "_")
+	if v.envPrefix != "" {
+		name = v.envPrefix + "_" + name
+	}
+	return strings.ToUpper(name)
+}
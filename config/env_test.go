@@ -0,0 +1,72 @@
+package config_load
+
+import (
+	"errors"
+	"testing"
+)
+
+type envConfig struct {
+	App struct {
+		Name string `mapstructure:"name"`
+	} `mapstructure:"app"`
+	DB struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Password string `mapstructure:"password" env:"DATABASE_PASSWORD"`
+	} `mapstructure:"db"`
+}
+
+func TestLoad_EnvFillsKeysAbsentFromFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: from-file\n")
+	t.Setenv("APP_DB_HOST", "env-host")
+	t.Setenv("APP_DB_PORT", "6543")
+	t.Setenv("DATABASE_PASSWORD", "s3cret")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg envConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "from-file" || cfg.DB.Host != "env-host" || cfg.DB.Port != 6543 || cfg.DB.Password != "s3cret" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	for _, k := range exp.Keys {
+		if k.Key == "db.password" && (k.Layer != LayerEnv || k.Origin != "DATABASE_PASSWORD" || k.EnvVar != "DATABASE_PASSWORD") {
+			t.Fatalf("expected db.password from DATABASE_PASSWORD, got %+v", k)
+		}
+	}
+}
+
+func TestLoad_EnvOnlyWithOptionalFile(t *testing.T) {
+	t.Setenv("APP_APP_NAME", "env-only")
+	t.Setenv("APP_DB_HOST", "env-host")
+
+	loader := New("APP", "", "",
+		WithConfigFileSearchPaths(t.TempDir()),
+		WithStructTagName("mapstructure"),
+		WithConfigFileOptional(),
+	)
+	var cfg envConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "env-only" || cfg.DB.Host != "env-host" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_MissingFileStillFailsByDefault(t *testing.T) {
+	t.Setenv("APP_DB_HOST", "env-host")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(t.TempDir()), WithStructTagName("mapstructure"))
+	var cfg envConfig
+	if err := loader.Load(&cfg); !errors.Is(err, ErrConfigFileNotFound) {
+		t.Fatalf("expected ErrConfigFileNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package config_load
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Layer names the source a config value was taken from.
+type Layer string
+
+const (
+	LayerUnset    Layer = "unset"
+	LayerDefault  Layer = "default"
+	LayerFile     Layer = "file"
+	LayerEnv      Layer = "env"
+	LayerFlag     Layer = "flag"
+	LayerRemote   Layer = "remote"
+	LayerSnapshot Layer = "snapshot"
+)
+
+// redacted replaces the value of secret keys in an Explanation.
+const redacted = "******"
+
+// KeyExplanation reports where the final value of one leaf key came from.
+type KeyExplanation struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Layer  Layer       `json:"source" yaml:"source"`
+	Origin string      `json:"origin,omitempty" yaml:"origin,omitempty"` // file path, env var, flag or Source name
+	EnvVar string      `json:"env_var,omitempty" yaml:"env_var,omitempty"`
+	Secret bool        `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// Explanation is the effective config with provenance, one entry per leaf key in
+// struct order. Secret values are already masked.
+type Explanation struct {
+	Keys []KeyExplanation `json:"keys" yaml:"keys"`
+}
+
+// JSON renders e as indented JSON, e.g. for an admin endpoint.
+func (e *Explanation) JSON() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+// YAML renders e as YAML.
+func (e *Explanation) YAML() ([]byte, error) {
+	return yaml.Marshal(e)
+}
+
+// Explain reports, for every leaf key of cfg, the final value, the layer it came from
+// and the env var that would override it. cfg must be the struct filled by the last
+// Load (or a value taken from Watcher.Current). Fields tagged `secret:"true"` and
+// keys resolved from a secret reference are masked.
+func (v *ViperLoader) Explain(cfg interface{}) (*Explanation, error) {
+	if !isStructPointer(cfg) {
+		return nil, ErrInvalidInput
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	root := reflect.ValueOf(cfg).Elem()
+	fields := structFields(root.Type(), v.tagName)
+	out := &Explanation{Keys: make([]KeyExplanation, 0, len(fields))}
+	for _, f := range fields {
+		ke := KeyExplanation{Key: f.path}
+		var envVars []string
+		if !v.fromRemote {
+			envVars = v.envVarsOf(f)
+			ke.EnvVar = envVars[len(envVars)-1]
+		}
+		ke.Layer, ke.Origin = v.layerOf(f.path, envVars)
+
+		_, isSecretRef := v.secretKeys[f.path]
+		ke.Secret = isSecretRef || f.sf.Tag.Get("secret") == "true"
+		switch val, ok := fieldValue(root, f.index); {
+		case ke.Secret:
+			ke.Value = redacted
+		case ok:
+			ke.Value = displayValue(val)
+		}
+		out.Keys = append(out.Keys, ke)
+	}
+	return out, nil
+}
+
+// layerOf mirrors viper's precedence for key: flags, env, then the loaded config, then
+// defaults.
+func (v *ViperLoader) layerOf(key string, envVars []string) (Layer, string) {
+	if fl, ok := v.changedFlag(key); ok {
+		return LayerFlag, "--" + fl.Name
+	}
+	for _, name := range envVars {
+		if val, ok := os.LookupEnv(name); ok && val != "" {
+			return LayerEnv, name
+		}
+	}
+	if v.InConfig(key) {
+		if v.fromRemote {
+			return LayerRemote, v.source.Name()
+		}
+		if origin, ok := v.keyOrigins[strings.ToLower(key)]; ok {
+			return origin.layer, origin.path
+		}
+		return LayerFile, v.ConfigFileUsed()
+	}
+	if v.IsSet(key) {
+		return LayerDefault, ""
+	}
+	return LayerUnset, ""
+}
+
+// displayValue converts types with a poor JSON/YAML form (durations, TextMarshalers)
+// to their text form.
+func displayValue(val reflect.Value) interface{} {
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Type() == durationType {
+		return time.Duration(val.Int()).String()
+	}
+	if m, ok := val.Interface().(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	if s, ok := val.Interface().(fmt.Stringer); ok && val.Kind() == reflect.Struct {
+		return s.String()
+	}
+	return val.Interface()
+}
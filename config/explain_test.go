@@ -0,0 +1,94 @@
+package config_load
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type explainConfig struct {
+	App struct {
+		Name    string        `mapstructure:"name"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+	} `mapstructure:"app"`
+	DB struct {
+		Host     string `mapstructure:"host"`
+		Password string `mapstructure:"password" secret:"true"`
+		Token    string `mapstructure:"token"`
+		Replica  string `mapstructure:"replica"`
+	} `mapstructure:"db"`
+}
+
+func TestExplain_ReportsLayersAndMasksSecrets(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("tok"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfgFile := writeTempYAML(t, dir, "config.yaml", `
+app:
+  name: fromfile
+db:
+  host: file-host
+  password: hunter2
+  token: "${file:`+tokenFile+`}"
+`)
+	t.Setenv("APP_DB_HOST", "env-host")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg explainConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	byKey := map[string]KeyExplanation{}
+	for _, k := range exp.Keys {
+		byKey[k.Key] = k
+	}
+
+	check := func(key string, layer Layer, value interface{}) {
+		t.Helper()
+		k, ok := byKey[key]
+		if !ok {
+			t.Fatalf("key %s missing from explanation", key)
+		}
+		if k.Layer != layer || k.Value != value {
+			t.Errorf("%s: got (%s, %v), want (%s, %v)", key, k.Layer, k.Value, layer, value)
+		}
+	}
+	check("app.name", LayerFile, "fromfile")
+	check("app.timeout", LayerDefault, "30s")
+	check("db.host", LayerEnv, "env-host")
+	check("db.password", LayerFile, redacted)
+	check("db.token", LayerFile, redacted)
+	check("db.replica", LayerUnset, "")
+
+	if got := byKey["app.name"].Origin; got != cfgFile {
+		t.Errorf("app.name origin = %q, want %q", got, cfgFile)
+	}
+	if got := byKey["db.replica"].EnvVar; got != "APP_DB_REPLICA" {
+		t.Errorf("db.replica env var = %q", got)
+	}
+
+	js, err := exp.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(js), "hunter2") || strings.Contains(string(js), `"tok"`) {
+		t.Fatalf("secret leaked in JSON: %s", js)
+	}
+	var decoded Explanation
+	if err := json.Unmarshal(js, &decoded); err != nil || len(decoded.Keys) != len(exp.Keys) {
+		t.Fatalf("JSON round trip failed: %v", err)
+	}
+	ym, err := exp.YAML()
+	if err != nil || strings.Contains(string(ym), "hunter2") {
+		t.Fatalf("YAML render: %v\n%s", err, ym)
+	}
+}
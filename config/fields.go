@@ -0,0 +1,105 @@
+package config_load
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// field is a leaf of the config struct addressed by the key the decoder reads it from.
+type field struct {
+	path  string // dotted key as written in the struct tags, e.g. "db.pool.max"
+	index []int  // reflect index path from the root struct
+	sf    reflect.StructField
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+)
+
+// structFields walks t depth-first and returns every leaf field, naming each level
+// with tagName the same way the mapstructure decoder does: the tag name when set,
+// the Go field name otherwise, "-" skips the field and ",squash" flattens it.
+func structFields(t reflect.Type, tagName string) []field {
+	var out []field
+	walkStruct(t, tagName, "", nil, &out)
+	return out
+}
+
+func walkStruct(t reflect.Type, tagName, prefix string, index []int, out *[]field) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, squash, skip := fieldKey(sf, tagName)
+		if skip {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if squash {
+			path = prefix
+		}
+		if isNested(sf.Type) {
+			walkStruct(sf.Type, tagName, path, idx, out)
+			continue
+		}
+		*out = append(*out, field{path: path, index: idx, sf: sf})
+	}
+}
+
+func fieldKey(sf reflect.StructField, tagName string) (name string, squash, skip bool) {
+	tag := sf.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, squash, false
+}
+
+// isNested reports whether t is a struct the walker should descend into rather
+// than a leaf value decoded as a whole.
+func isNested(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || t == urlType {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// fieldValue follows index from root. It returns false when a nil pointer on the
+// way makes the field unreachable.
+func fieldValue(root reflect.Value, index []int) (reflect.Value, bool) {
+	v := root
+	for _, i := range index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
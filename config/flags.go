@@ -0,0 +1,105 @@
+package config_load
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// BindFlags adds a flag named after the key path (e.g. --db.host) to fs for every leaf
+// of cfg. The flag's help text comes from the field's `usage` tag and its default from
+// the `default` tag. Call it before fs.Parse; flags set on the command line override
+// env, file and remote values.
+//
+// Only the generated flags that were set on the command line are bound, at Load: an
+// unset flag's zero default (e.g. "" for a map or time.Time field) never reaches the
+// decoder. Other flags already in fs (e.g. --verbose) do not become config keys.
+func (v *ViperLoader) BindFlags(fs *pflag.FlagSet, cfg interface{}) error {
+	if !isStructPointer(cfg) || fs == nil {
+		return ErrInvalidInput
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	generated := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	for _, f := range structFields(reflect.TypeOf(cfg).Elem(), v.tagName) {
+		if fs.Lookup(f.path) != nil {
+			return fmt.Errorf("config: flag --%s already defined", f.path)
+		}
+		addFlag(generated, f)
+		if raw, ok := f.sf.Tag.Lookup("default"); ok {
+			fl := generated.Lookup(f.path)
+			if err := fl.Value.Set(raw); err != nil {
+				return fmt.Errorf("config: default for %s: %w", f.path, err)
+			}
+			fl.DefValue = fl.Value.String()
+		}
+	}
+	fs.AddFlagSet(generated)
+	v.flags = generated
+	return nil
+}
+
+// bindChangedFlags binds the generated flags set on the command line. Binding is
+// deferred to Load because viper falls back to a bound flag's default when no other
+// layer has the key, and the defaults of the generated flags are placeholders.
+func (v *ViperLoader) bindChangedFlags() error {
+	if v.flags == nil {
+		return nil
+	}
+	var err error
+	// VisitAll plus Changed: fs parses, so v.flags never records which flags were set.
+	v.flags.VisitAll(func(fl *pflag.Flag) {
+		if fl.Changed && err == nil {
+			err = v.BindPFlag(fl.Name, fl)
+		}
+	})
+	return err
+}
+
+// addFlag defines a flag typed after f so --help shows a meaningful type and values
+// are checked when parsed. Types without a matching flag kind take a string, which
+// the decoder converts like any other string value.
+func addFlag(fs *pflag.FlagSet, f field) {
+	name, usage := f.path, f.sf.Tag.Get("usage")
+	t := f.sf.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		fs.Duration(name, 0, usage)
+		return
+	}
+	if t == urlType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		fs.String(name, "", usage)
+		return
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		fs.Bool(name, false, usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fs.Int64(name, 0, usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fs.Uint64(name, 0, usage)
+	case reflect.Float32, reflect.Float64:
+		fs.Float64(name, 0, usage)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			fs.StringSlice(name, nil, usage)
+			return
+		}
+		fs.String(name, "", usage)
+	default:
+		fs.String(name, "", usage)
+	}
+}
+
+// changedFlag reports the flag for key when it was set on the command line.
+func (v *ViperLoader) changedFlag(key string) (*pflag.Flag, bool) {
+	if v.flags == nil {
+		return nil, false
+	}
+	fl := v.flags.Lookup(key)
+	return fl, fl != nil && fl.Changed
+}
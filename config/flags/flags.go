@@ -0,0 +1,203 @@
+// Package flags evaluates feature flags declared in config loaded by config_load.
+//
+// Flags live under the top-level "flags" key of the same file (or Consul key) the
+// loader reads, and are hot reloaded through ViperLoader.Watch:
+//
+//	flags:
+//	  new_checkout:
+//	    enabled: true
+//	    percentage: 25        # rolled out to 25% of keys
+//	  search_ranker:
+//	    enabled: true
+//	    variants:             # weights, need not add up to 100
+//	      control: 50
+//	      bm25: 30
+//	      neural: 20
+//
+// Rollouts hash the flag name with a key taken from the request context (see
+// ContextWithKey), so the same user always lands in the same bucket.
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync/atomic"
+
+	config_load "github.com/viantonugroho11/go-lib/config"
+)
+
+// Flag is one declared flag. Field names double as config keys, so the document
+// decodes whatever struct tag name the loader was built with.
+type Flag struct {
+	// Enabled is the kill switch: a disabled flag is off for everyone.
+	Enabled bool
+	// Percentage rolls the flag out to a share of keys, 0-100. Unset means 100.
+	Percentage *float64
+	// Variants maps variant names to relative weights for Variant.
+	Variants map[string]float64
+}
+
+// document is the part of the config the flags package reads.
+type document struct {
+	Flags map[string]Flag
+}
+
+// KeyFunc extracts the rollout key (user ID, tenant ID, ...) from ctx.
+// Default: KeyFromContext; override via WithKeyFunc.
+type KeyFunc func(ctx context.Context) string
+
+type ctxKey struct{}
+
+// ContextWithKey returns ctx with the rollout key stored under the package's default key.
+// Use in HTTP middleware once the user or tenant is known.
+func ContextWithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, key)
+}
+
+// KeyFromContext returns the rollout key stored in ctx, or "" if none.
+func KeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(ctxKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithKeyFunc sets how the rollout key is read from the request context.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.keyFunc = fn
+		}
+	}
+}
+
+// Client evaluates flags against the latest config. It is safe for concurrent use;
+// each evaluation reads the current config with a single atomic load.
+type Client struct {
+	store   *config_load.Store[document]
+	keyFunc KeyFunc
+}
+
+// New loads the flags through loader and keeps them up to date until ctx is done.
+// Reload errors go to the loader's WithErrorHandler and keep the previous flags.
+// loader may be the one the app's own NewStore or Watch uses; each reloads on its own.
+func New(ctx context.Context, loader *config_load.ViperLoader, opts ...Option) (*Client, error) {
+	store, err := config_load.NewStore[document](ctx, loader)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{store: store, keyFunc: KeyFromContext}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Enabled reports whether flag name is on for the key in ctx. Unknown and disabled
+// flags are off. A partial rollout is off when ctx carries no key.
+func (c *Client) Enabled(ctx context.Context, name string) bool {
+	f, ok := c.store.Get().Flags[name]
+	if !ok || !f.Enabled {
+		return false
+	}
+	if f.Percentage == nil || *f.Percentage >= 100 {
+		return true
+	}
+	key := c.keyFunc(ctx)
+	if key == "" || *f.Percentage <= 0 {
+		return false
+	}
+	return bucket(name, key) < *f.Percentage
+}
+
+// Variant returns the variant of flag name assigned to the key in ctx, weighted by
+// the declared variants. It returns "" when the flag is off for the key (see Enabled),
+// declares no variants, or ctx carries no key.
+func (c *Client) Variant(ctx context.Context, name string) string {
+	if !c.Enabled(ctx, name) {
+		return ""
+	}
+	f := c.store.Get().Flags[name]
+	key := c.keyFunc(ctx)
+	if key == "" || len(f.Variants) == 0 {
+		return ""
+	}
+	// Sort so the assignment does not depend on map iteration order.
+	names := make([]string, 0, len(f.Variants))
+	var total float64
+	for v, w := range f.Variants {
+		if w > 0 {
+			names = append(names, v)
+			total += w
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	// Hash under a different seed than the rollout so variant and rollout buckets
+	// are independent.
+	point := bucket(name+"/variant", key) / 100 * total
+	for _, v := range names {
+		point -= f.Variants[v]
+		if point < 0 {
+			return v
+		}
+	}
+	return names[len(names)-1]
+}
+
+// bucket maps (name, key) to a stable point in [0, 100).
+func bucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// --- Global default client ---
+//
+// Request handlers call flags.Enabled(ctx, name) without threading a Client through
+// every layer. Until SetDefault is called every flag is off.
+
+// clientBox keeps the concrete type stored in atomic.Value consistent across Stores.
+type clientBox struct{ c *Client }
+
+var defaultClient atomic.Value // holds clientBox
+
+// SetDefault installs the package-wide client used by Enabled and Variant.
+func SetDefault(c *Client) {
+	defaultClient.Store(clientBox{c: c})
+}
+
+// Default returns the installed client, or nil.
+func Default() *Client {
+	if b, ok := defaultClient.Load().(clientBox); ok {
+		return b.c
+	}
+	return nil
+}
+
+// Enabled evaluates name with the default client. It is false until SetDefault is called.
+func Enabled(ctx context.Context, name string) bool {
+	if c := Default(); c != nil {
+		return c.Enabled(ctx, name)
+	}
+	return false
+}
+
+// Variant evaluates name with the default client. It is "" until SetDefault is called.
+func Variant(ctx context.Context, name string) string {
+	if c := Default(); c != nil {
+		return c.Variant(ctx, name)
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config_load "github.com/viantonugroho11/go-lib/config"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newClient(t *testing.T, ctx context.Context, dir string) *Client {
+	t.Helper()
+	loader := config_load.New("APP", "", "", config_load.WithConfigFileSearchPaths(dir))
+	c, err := New(ctx, loader)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+const flagsYAML = `
+app:
+  name: shop
+flags:
+  on:
+    enabled: true
+  off:
+    enabled: false
+    percentage: 100
+  half:
+    enabled: true
+    percentage: 50
+  ranker:
+    enabled: true
+    variants:
+      control: 50
+      bm25: 25
+      neural: 25
+`
+
+func TestClient_Enabled(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, flagsYAML)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newClient(t, ctx, dir)
+
+	user := ContextWithKey(ctx, "user-1")
+	if !c.Enabled(user, "on") || c.Enabled(user, "off") || c.Enabled(user, "missing") {
+		t.Fatal("unexpected boolean flag result")
+	}
+	if c.Enabled(ctx, "half") {
+		t.Fatal("a partial rollout must be off without a key")
+	}
+
+	on := 0
+	for i := 0; i < 2000; i++ {
+		k := ContextWithKey(ctx, fmt.Sprintf("user-%d", i))
+		got := c.Enabled(k, "half")
+		if got != c.Enabled(k, "half") {
+			t.Fatal("rollout must be stable for a key")
+		}
+		if got {
+			on++
+		}
+	}
+	if on < 850 || on > 1150 {
+		t.Fatalf("expected about half of 2000 keys enabled, got %d", on)
+	}
+}
+
+func TestClient_Variant(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, flagsYAML)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newClient(t, ctx, dir)
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[c.Variant(ContextWithKey(ctx, fmt.Sprintf("user-%d", i)), "ranker")]++
+	}
+	if counts["control"] < 1700 || counts["control"] > 2300 || counts["bm25"] < 800 || counts["neural"] < 800 || counts[""] != 0 {
+		t.Fatalf("unexpected variant distribution: %v", counts)
+	}
+	if got := c.Variant(ctx, "ranker"); got != "" {
+		t.Fatalf("expected no variant without a key, got %q", got)
+	}
+	if got := c.Variant(ContextWithKey(ctx, "u"), "on"); got != "" {
+		t.Fatalf("expected no variant for a flag without variants, got %q", got)
+	}
+}
+
+func TestClient_HotReloadAndDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "flags:\n  new_checkout:\n    enabled: false\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if Enabled(ctx, "new_checkout") {
+		t.Fatal("flags must be off before SetDefault")
+	}
+	SetDefault(newClient(t, ctx, dir))
+	defer SetDefault(nil)
+	if Enabled(ctx, "new_checkout") {
+		t.Fatal("expected new_checkout off")
+	}
+
+	writeConfig(t, dir, "flags:\n  new_checkout:\n    enabled: true\n")
+	deadline := time.Now().Add(3 * time.Second)
+	for !Enabled(ctx, "new_checkout") {
+		if time.Now().After(deadline) {
+			t.Fatal("flag change was not picked up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestClient_WithKeyFunc(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "flags:\n  half:\n    enabled: true\n    percentage: 50\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type tenantKey struct{}
+	loader := config_load.New("APP", "", "", config_load.WithConfigFileSearchPaths(dir))
+	c, err := New(ctx, loader, WithKeyFunc(func(ctx context.Context) string {
+		s, _ := ctx.Value(tenantKey{}).(string)
+		return s
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sawOn, sawOff bool
+	for i := 0; i < 50; i++ {
+		if c.Enabled(context.WithValue(ctx, tenantKey{}, fmt.Sprint("tenant-", i)), "half") {
+			sawOn = true
+		} else {
+			sawOff = true
+		}
+	}
+	if !sawOn || !sawOff {
+		t.Fatal("expected the custom key to drive the rollout")
+	}
+}
+
+// TestClient_SharesRemoteLoaderWithStore covers the usual setup: the app's config and
+// its flags come from one remote key through one loader, and both hot reload.
+func TestClient_SharesRemoteLoaderWithStore(t *testing.T) {
+	var body atomic.Value
+	body.Store("app:\n  name: v1\nflags:\n  new_checkout:\n    enabled: false\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type appConfig struct {
+		App struct{ Name string }
+	}
+	loader := config_load.New("APP", "", "",
+		config_load.WithSource(config_load.NewHTTPSource(srv.URL+"/config.yaml",
+			config_load.WithSourcePollInterval(20*time.Millisecond))),
+		config_load.WithConfigFileSearchPaths(t.TempDir()),
+	)
+	store, err := config_load.NewStore[appConfig](ctx, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(ctx, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Get().App.Name != "v1" || c.Enabled(ctx, "new_checkout") {
+		t.Fatalf("unexpected initial state: %+v", store.Get())
+	}
+
+	body.Store("app:\n  name: v2\nflags:\n  new_checkout:\n    enabled: true\n")
+	deadline := time.Now().Add(3 * time.Second)
+	for store.Get().App.Name != "v2" || !c.Enabled(ctx, "new_checkout") {
+		if time.Now().After(deadline) {
+			t.Fatalf("both must reload: app %q, flag %v", store.Get().App.Name, c.Enabled(ctx, "new_checkout"))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,126 @@
+package config_load
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type flagConfig struct {
+	DB struct {
+		Host    string        `mapstructure:"host" usage:"database host"`
+		Port    int           `mapstructure:"port" default:"5432" usage:"database port"`
+		Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+	} `mapstructure:"db"`
+	Debug bool     `mapstructure:"debug" usage:"verbose logging"`
+	Tags  []string `mapstructure:"tags"`
+}
+
+func TestBindFlags_OverrideEnvAndFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "db:\n  host: file-host\n  port: 1111\ntags: [a]\n")
+	t.Setenv("APP_DB_HOST", "env-host")
+	t.Setenv("APP_DB_PORT", "2222")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	fs := pflag.NewFlagSet("job", pflag.ContinueOnError)
+	fs.Bool("dry-run", false, "unrelated flag")
+	var cfg flagConfig
+	if err := loader.BindFlags(fs, &cfg); err != nil {
+		t.Fatalf("bind flags: %v", err)
+	}
+	if err := fs.Parse([]string{"--db.host=flag-host", "--debug", "--tags=x,y", "--dry-run"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DB.Host != "flag-host" {
+		t.Fatalf("flag must win over env and file, got %q", cfg.DB.Host)
+	}
+	if cfg.DB.Port != 2222 {
+		t.Fatalf("unset flag must not shadow env, got %d", cfg.DB.Port)
+	}
+	if cfg.DB.Timeout != 5*time.Second || !cfg.Debug || strings.Join(cfg.Tags, ",") != "x,y" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if loader.IsSet("dry-run") {
+		t.Fatal("flags not generated from the struct must not become config keys")
+	}
+
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	for _, k := range exp.Keys {
+		switch k.Key {
+		case "db.host":
+			if k.Layer != LayerFlag || k.Origin != "--db.host" {
+				t.Fatalf("expected db.host from --db.host, got %+v", k)
+			}
+		case "db.port":
+			if k.Layer != LayerEnv {
+				t.Fatalf("expected db.port from env, got %+v", k)
+			}
+		}
+	}
+}
+
+func TestBindFlags_HelpListsKeys(t *testing.T) {
+	loader := New("APP", "", "", WithStructTagName("mapstructure"))
+	fs := pflag.NewFlagSet("job", pflag.ContinueOnError)
+	if err := loader.BindFlags(fs, &flagConfig{}); err != nil {
+		t.Fatalf("bind flags: %v", err)
+	}
+	var help bytes.Buffer
+	fs.SetOutput(&help)
+	fs.PrintDefaults()
+	for _, want := range []string{"--db.host string", "database host", "--db.port int", "(default 5432)", "--db.timeout duration", "(default 5s)", "--debug", "--tags strings"} {
+		if !strings.Contains(help.String(), want) {
+			t.Errorf("help is missing %q:\n%s", want, help.String())
+		}
+	}
+}
+
+func TestBindFlags_DuplicateFlag(t *testing.T) {
+	loader := New("APP", "", "", WithStructTagName("mapstructure"))
+	fs := pflag.NewFlagSet("job", pflag.ContinueOnError)
+	fs.String("debug", "", "")
+	if err := loader.BindFlags(fs, &flagConfig{}); err == nil || !strings.Contains(err.Error(), "--debug") {
+		t.Fatalf("expected duplicate flag error, got %v", err)
+	}
+}
+
+func TestBindFlags_UnsetFlagsDoNotReachDecoder(t *testing.T) {
+	type cfgT struct {
+		Labels   map[string]string `mapstructure:"labels"`
+		Since    time.Time         `mapstructure:"since"`
+		Endpoint *url.URL          `mapstructure:"endpoint"`
+		Name     string            `mapstructure:"name"`
+	}
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "name: file\n")
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	fs := pflag.NewFlagSet("job", pflag.ContinueOnError)
+	var cfg cfgT
+	if err := loader.BindFlags(fs, &cfg); err != nil {
+		t.Fatalf("bind flags: %v", err)
+	}
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unset flags must not fail Load: %v", err)
+	}
+	if cfg.Labels != nil || !cfg.Since.IsZero() || cfg.Endpoint != nil || cfg.Name != "file" {
+		t.Fatalf("unset flags leaked into config: %+v", cfg)
+	}
+	if loader.IsSet("since") {
+		t.Fatal("an unset flag must not make its key set")
+	}
+}
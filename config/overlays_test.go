@@ -0,0 +1,95 @@
+package config_load
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+type overlayConfig struct {
+	App struct {
+		Name  string `mapstructure:"name"`
+		Debug bool   `mapstructure:"debug"`
+	} `mapstructure:"app"`
+	DB struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+		User string `mapstructure:"user"`
+	} `mapstructure:"db"`
+}
+
+func TestLoad_OverlaysDeepMergeInOrder(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTempYAML(t, dir, "config.yaml", `
+app:
+  name: base
+db:
+  host: localhost
+  port: 5432
+  user: app
+`)
+	prod := writeTempYAML(t, dir, "config.prod.yaml", `
+db:
+  host: prod-db
+  port: 6432
+`)
+	local := writeTempYAML(t, dir, "config.local.yaml", `
+db:
+  port: 7000
+app:
+  debug: true
+`)
+	t.Setenv("APP_ENV", "prod")
+
+	loader := New("APP", "", "",
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithConfigOverlays("config", "config.${APP_ENV}", "config.missing", "config.local"),
+	)
+	var cfg overlayConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "base" || cfg.DB.User != "app" {
+		t.Fatalf("base keys lost in merge: %+v", cfg)
+	}
+	if cfg.DB.Host != "prod-db" || cfg.DB.Port != 7000 || !cfg.App.Debug {
+		t.Fatalf("overlays not applied in order: %+v", cfg)
+	}
+
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origins := map[string]string{}
+	for _, k := range exp.Keys {
+		origins[k.Key] = k.Origin
+	}
+	want := map[string]string{
+		"app.name":  base,
+		"db.user":   base,
+		"db.host":   prod,
+		"db.port":   local,
+		"app.debug": local,
+	}
+	for key, path := range want {
+		if filepath.Clean(origins[key]) != filepath.Clean(path) {
+			t.Errorf("%s origin = %q, want %q", key, origins[key], path)
+		}
+	}
+}
+
+func TestLoad_OverlaysSkipUnsetEnvAndRequireBase(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.local.yaml", "app:\n  name: local\n")
+	t.Setenv("APP_ENV", "")
+
+	loader := New("APP", "", "",
+		WithConfigFileSearchPaths(dir),
+		WithConfigOverlays("config", "config.${APP_ENV}", "config.local"),
+	)
+	var cfg overlayConfig
+	if err := loader.Load(&cfg); !errors.Is(err, ErrConfigFileNotFound) {
+		t.Fatalf("expected ErrConfigFileNotFound for missing base, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package config_load
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AttemptError is the failure of a single attempt to read the remote config.
+type AttemptError struct {
+	Attempt int
+	Err     error
+}
+
+// RemoteLoadError records every failed attempt to read config from a remote Source.
+// errors.Is/As see each attempt's error, including context.Canceled or
+// context.DeadlineExceeded when the load was interrupted.
+type RemoteLoadError struct {
+	Source   string // Source.Name()
+	Attempts []AttemptError
+}
+
+func (e *RemoteLoadError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "remote load failed (%s) after %d attempt(s)", e.Source, len(e.Attempts))
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "; attempt %d: %v", a.Attempt, a.Err)
+	}
+	return b.String()
+}
+
+func (e *RemoteLoadError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
@@ -0,0 +1,88 @@
+package config_load
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoadContext_CancelStopsConsulRetries(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: x\n")
+	fc.setDown(true)
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: fromfile\n")
+
+	loader := New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(100),
+		WithRemoteBackoff(time.Second, time.Second),
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var cfg appConfig
+	err := loader.LoadContext(ctx, &cfg)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("LoadContext ignored the deadline, took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got: %v", err)
+	}
+	var rerr *RemoteLoadError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected *RemoteLoadError, got %T", err)
+	}
+	if len(rerr.Attempts) != 2 || rerr.Attempts[0].Err == nil {
+		t.Fatalf("expected the failed attempt and the cancellation, got %+v", rerr.Attempts)
+	}
+	if cfg.App.Name != "" {
+		t.Fatalf("cancelled load must not fall back to file, got %q", cfg.App.Name)
+	}
+}
+
+func TestLoad_ConsulFailureReportedToErrorHandler(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "")
+	fc.setDown(true)
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: fromfile\n")
+
+	var reported error
+	loader := New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(3),
+		WithRemoteBackoff(time.Millisecond, 2*time.Millisecond),
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithErrorHandler(func(err error) { reported = err }),
+	)
+	var cfg appConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "fromfile" {
+		t.Fatalf("expected fallback to file, got %q", cfg.App.Name)
+	}
+	var rerr *RemoteLoadError
+	if !errors.As(reported, &rerr) || len(rerr.Attempts) != 3 {
+		t.Fatalf("expected *RemoteLoadError with 3 attempts reported, got %v", reported)
+	}
+}
+
+func TestBackoff_GrowsExponentiallyUpToMax(t *testing.T) {
+	v := New("APP", "", "", WithRemoteBackoff(100*time.Millisecond, time.Second), WithRemoteBackoffJitter(0))
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := v.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("attempt %d: backoff = %s, want %s", i+1, got, w*time.Millisecond)
+		}
+	}
+
+	v = New("APP", "", "", WithRemoteBackoff(100*time.Millisecond, time.Second), WithRemoteBackoffJitter(0.5))
+	for i := 0; i < 50; i++ {
+		if got := v.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("jittered backoff %s outside ±50%%", got)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package config_load
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// schemaDraft is the JSON Schema dialect emitted by Schema.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the strings time.ParseDuration accepts.
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// configNode is one key of the config file: a leaf field or an object of children
+// in struct order.
+type configNode struct {
+	name     string
+	field    *field
+	children []*configNode
+}
+
+// configTree nests the leaves of t by their dotted key path.
+func configTree(t reflect.Type, tagName string) *configNode {
+	root := &configNode{}
+	for _, f := range structFields(t, tagName) {
+		f := f
+		node := root
+		for _, seg := range strings.Split(f.path, ".") {
+			node = node.child(seg)
+		}
+		node.field = &f
+	}
+	return root
+}
+
+func (n *configNode) child(name string) *configNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &configNode{name: name}
+	n.children = append(n.children, c)
+	return c
+}
+
+// required reports whether the node must be present in the file: a leaf with a
+// `required` rule, or an object holding one. A leaf with a `default` tag, or tagged
+// `schema:"optional"` because env, a flag or the remote source supplies it, is left out.
+func (n *configNode) required() bool {
+	if n.field != nil {
+		sf := n.field.sf
+		if _, ok := sf.Tag.Lookup("default"); ok || sf.Tag.Get("schema") == "optional" {
+			return false
+		}
+		for _, rule := range validateRules(sf) {
+			if rule == "required" {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range n.children {
+		if c.required() {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRules(sf reflect.StructField) []string {
+	var rules []string
+	for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+		if rule = strings.TrimSpace(rule); rule != "" && rule != "-" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// optionsTagName returns the struct tag name a loader built with opts would use.
+func optionsTagName(opts []Option) string {
+	v := &ViperLoader{tagName: "json"}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v.tagName
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing the config file cfg is
+// loaded from, for linting config files in CI or publishing docs. Keys are named like
+// Load names them, so pass the same WithStructTagName option given to New.
+//
+// Each key carries its type, the `default` tag as default, the `usage` tag as
+// description and the `validate` rules as required, minimum/maximum (or the
+// length/items variants), enum and format. A `required` key that has a default or is
+// tagged `schema:"optional"` is not required in the file.
+func Schema(cfg interface{}, opts ...Option) ([]byte, error) {
+	if !isStructPointer(cfg) {
+		return nil, ErrInvalidInput
+	}
+	tagName := optionsTagName(opts)
+	s, err := objectSchema(configTree(reflect.TypeOf(cfg).Elem(), tagName), tagName)
+	if err != nil {
+		return nil, err
+	}
+	s["$schema"] = schemaDraft
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func objectSchema(n *configNode, tagName string) (map[string]interface{}, error) {
+	props := make(map[string]interface{}, len(n.children))
+	var required []string
+	for _, c := range n.children {
+		var (
+			s   map[string]interface{}
+			err error
+		)
+		if c.field != nil {
+			s, err = leafSchema(*c.field, tagName)
+		} else {
+			s, err = objectSchema(c, tagName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		props[c.name] = s
+		if c.required() {
+			required = append(required, c.name)
+		}
+	}
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s, nil
+}
+
+func leafSchema(f field, tagName string) (map[string]interface{}, error) {
+	s := typeSchema(f.sf.Type, tagName)
+	if usage := f.sf.Tag.Get("usage"); usage != "" {
+		s["description"] = usage
+	}
+	if raw, ok := f.sf.Tag.Lookup("default"); ok {
+		val, err := parseDefault(f.sf.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: default for %s: %w", f.path, err)
+		}
+		s["default"] = plainValue(val)
+	}
+	if f.sf.Tag.Get("secret") == "true" {
+		s["writeOnly"] = true
+	}
+	for _, rule := range validateRules(f.sf) {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			bound, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("config: %s: invalid rule %q", f.path, rule)
+			}
+			s[boundKeyword(s["type"], name)] = bound
+		case "oneof":
+			var enum []interface{}
+			for _, opt := range strings.Fields(arg) {
+				val, err := parseDefault(f.sf.Type, opt)
+				if err != nil {
+					val = opt
+				}
+				enum = append(enum, plainValue(val))
+			}
+			s["enum"] = enum
+		case "url":
+			s["format"] = "uri"
+		case "duration":
+			s["pattern"] = durationPattern
+		}
+	}
+	return s, nil
+}
+
+// boundKeyword maps a min/max rule to the keyword validate applies for the type:
+// the value for numbers, the length for strings, the item count for arrays and maps.
+func boundKeyword(typ interface{}, rule string) string {
+	switch typ {
+	case "string":
+		return rule + "Length"
+	case "array":
+		return rule + "Items"
+	case "object":
+		return rule + "Properties"
+	}
+	return rule + "imum"
+}
+
+// typeSchema describes the value the decoder accepts for t.
+func typeSchema(t reflect.Type, tagName string) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		return map[string]interface{}{"type": "string", "pattern": durationPattern}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == urlType:
+		return map[string]interface{}{"type": "string", "format": "uri"}
+	case t == byteSizeType:
+		return map[string]interface{}{"type": []string{"integer", "string"}, "pattern": byteSizePattern}
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), tagName)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), tagName)}
+	case reflect.Struct:
+		s, err := objectSchema(configTree(t, tagName), tagName)
+		if err != nil {
+			return map[string]interface{}{"type": "object"}
+		}
+		return s
+	case reflect.Interface:
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// plainValue turns parsed defaults into values that render as the user wrote them.
+func plainValue(val interface{}) interface{} {
+	switch x := val.(type) {
+	case time.Duration:
+		return x.String()
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, el := range x {
+			out[i] = plainValue(el)
+		}
+		return out
+	}
+	return val
+}
+
+// SampleYAML renders an example config file for cfg: every key in struct order, set
+// to its `default` tag or an empty value, with the `usage` tag and validation rules
+// as comments. Pass the same WithStructTagName option given to New.
+func SampleYAML(cfg interface{}, opts ...Option) ([]byte, error) {
+	if !isStructPointer(cfg) {
+		return nil, ErrInvalidInput
+	}
+	root, err := sampleNode(configTree(reflect.TypeOf(cfg).Elem(), optionsTagName(opts)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sampleNode(n *configNode) (*yaml.Node, error) {
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	for _, c := range n.children {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: c.name}
+		var (
+			val *yaml.Node
+			err error
+		)
+		if c.field != nil {
+			key.HeadComment = sampleComment(c.field.sf)
+			val, err = sampleValue(*c.field)
+		} else {
+			val, err = sampleNode(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.Content = append(m.Content, key, val)
+	}
+	return m, nil
+}
+
+func sampleComment(sf reflect.StructField) string {
+	var lines []string
+	if usage := sf.Tag.Get("usage"); usage != "" {
+		lines = append(lines, usage)
+	}
+	if rules := validateRules(sf); len(rules) > 0 {
+		lines = append(lines, "validate: "+strings.Join(rules, ", "))
+	}
+	if sf.Tag.Get("secret") == "true" {
+		lines = append(lines, "secret: prefer a ${file:...} or ${env:...} reference")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sampleValue(f field) (*yaml.Node, error) {
+	var val interface{}
+	if raw, ok := f.sf.Tag.Lookup("default"); ok {
+		parsed, err := parseDefault(f.sf.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: default for %s: %w", f.path, err)
+		}
+		val = plainValue(parsed)
+	} else {
+		val = zeroSample(f.sf.Type)
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(val); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// zeroSample is the placeholder for a key without a default.
+func zeroSample(t reflect.Type) interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return "0s"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0
+	case reflect.Float32, reflect.Float64:
+		return 0.0
+	case reflect.Slice, reflect.Array:
+		return []interface{}{}
+	case reflect.Map:
+		return map[string]interface{}{}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package config_load
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaConfig struct {
+	App struct {
+		Name string `mapstructure:"name" validate:"required" usage:"service name"`
+		Env  string `mapstructure:"env" default:"dev" validate:"oneof=dev staging prod"`
+	} `mapstructure:"app"`
+	HTTP struct {
+		Port    int           `mapstructure:"port" default:"8080" validate:"required,min=1,max=65535"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Origins []string      `mapstructure:"origins" default:"a.com,b.com"`
+	} `mapstructure:"http"`
+	DB struct {
+		URL      string `mapstructure:"url" validate:"url"`
+		Password string `mapstructure:"password" secret:"true" validate:"required" schema:"optional"`
+	} `mapstructure:"db"`
+}
+
+func TestSchema_DescribesKeys(t *testing.T) {
+	raw, err := Schema(&schemaConfig{}, WithStructTagName("mapstructure"))
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	at := func(path ...string) map[string]interface{} {
+		t.Helper()
+		node := s
+		for _, p := range path {
+			node = node["properties"].(map[string]interface{})[p].(map[string]interface{})
+		}
+		return node
+	}
+
+	// http.port has a default and db.password is schema:"optional", so only app is required.
+	if s["$schema"] != schemaDraft || !reflect.DeepEqual(s["required"], []interface{}{"app"}) {
+		t.Fatalf("unexpected root: %v", s)
+	}
+	if got := at("app")["required"]; !reflect.DeepEqual(got, []interface{}{"name"}) {
+		t.Fatalf("expected app.name required, got %v", got)
+	}
+	if got := at("app", "name")["description"]; got != "service name" {
+		t.Fatalf("expected usage as description, got %v", got)
+	}
+	if got := at("app", "env"); !reflect.DeepEqual(got["enum"], []interface{}{"dev", "staging", "prod"}) || got["default"] != "dev" {
+		t.Fatalf("unexpected app.env schema: %v", got)
+	}
+	port := at("http", "port")
+	if port["type"] != "integer" || port["minimum"] != 1.0 || port["maximum"] != 65535.0 || port["default"] != 8080.0 {
+		t.Fatalf("unexpected http.port schema: %v", port)
+	}
+	if got := at("http", "timeout"); got["default"] != "30s" || got["pattern"] != durationPattern {
+		t.Fatalf("unexpected http.timeout schema: %v", got)
+	}
+	if got := at("http", "origins"); got["type"] != "array" || !reflect.DeepEqual(got["default"], []interface{}{"a.com", "b.com"}) {
+		t.Fatalf("unexpected http.origins schema: %v", got)
+	}
+	if got := at("db", "url")["format"]; got != "uri" {
+		t.Fatalf("expected uri format, got %v", got)
+	}
+	if got := at("db", "password")["writeOnly"]; got != true {
+		t.Fatalf("expected secret to be writeOnly, got %v", got)
+	}
+}
+
+func TestSampleYAML_LoadsBack(t *testing.T) {
+	out, err := SampleYAML(&schemaConfig{}, WithStructTagName("mapstructure"))
+	if err != nil {
+		t.Fatalf("sample: %v", err)
+	}
+	sample := string(out)
+	for _, want := range []string{"# service name\n  # validate: required\n  name: \"\"", "port: 8080", "timeout: 30s", "# validate: oneof=dev staging prod"} {
+		if !strings.Contains(sample, want) {
+			t.Errorf("sample is missing %q:\n%s", want, sample)
+		}
+	}
+	if strings.Index(sample, "app:") > strings.Index(sample, "http:") {
+		t.Errorf("sample must keep struct order:\n%s", sample)
+	}
+
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", sample)
+	t.Setenv("APP_DB_PASSWORD", "s3cret") // schema:"optional": required, but not from the file
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg schemaConfig
+	err = loader.Load(&cfg)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Path != "app.name" {
+		t.Fatalf("expected the sample to load with only app.name missing, got %v", err)
+	}
+	if cfg.HTTP.Port != 8080 || cfg.HTTP.Timeout != 30*time.Second {
+		t.Fatalf("unexpected config from sample: %+v", cfg)
+	}
+}
+
+func TestSchema_InvalidInput(t *testing.T) {
+	if _, err := Schema(schemaConfig{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := SampleYAML(nil); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
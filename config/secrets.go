@@ -0,0 +1,144 @@
+package config_load
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretResolver resolves the reference part of a `${scheme:ref}` value, e.g. the path
+// in `${file:/run/secrets/db_pass}`. Register one per scheme with WithSecretResolver.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a plain function to SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithSecretResolver registers r for `${scheme:...}` references, replacing the
+// built-in resolver when scheme is "file" or "env".
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(v *ViperLoader) {
+		if scheme != "" && r != nil {
+			v.secretResolvers[scheme] = r
+		}
+	}
+}
+
+// fileSecretResolver reads the secret from a file, as mounted by Docker/Kubernetes
+// secrets. A single trailing newline is trimmed.
+type fileSecretResolver struct{}
+
+func (fileSecretResolver) Resolve(_ context.Context, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// envSecretResolver reads the secret from another environment variable.
+type envSecretResolver struct{}
+
+func (envSecretResolver) Resolve(_ context.Context, name string) (string, error) {
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return val, nil
+}
+
+var secretRefPattern = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]*)\}`)
+
+// resolveSecrets expands `${scheme:ref}` references in the string fields of cfg (and
+// string slices) using the registered resolvers, and records the key of every field
+// that held one. References with an unregistered scheme are left untouched.
+func (v *ViperLoader) resolveSecrets(ctx context.Context, cfg interface{}) error {
+	root := reflect.ValueOf(cfg).Elem()
+	secrets := make(map[string]struct{})
+	for _, f := range structFields(root.Type(), v.tagName) {
+		val, ok := fieldValue(root, f.index)
+		if !ok {
+			continue
+		}
+		for val.Kind() == reflect.Pointer && !val.IsNil() {
+			val = val.Elem()
+		}
+		var (
+			found bool
+			err   error
+		)
+		switch {
+		case val.Kind() == reflect.String:
+			found, err = v.expandSecret(ctx, val)
+		case val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.String:
+			for i := 0; i < val.Len() && err == nil; i++ {
+				var hit bool
+				hit, err = v.expandSecret(ctx, val.Index(i))
+				found = found || hit
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("config: resolve secret for %s: %w", f.path, err)
+		}
+		if found {
+			secrets[f.path] = struct{}{}
+		}
+	}
+	v.secretKeys = secrets
+	return nil
+}
+
+func (v *ViperLoader) expandSecret(ctx context.Context, val reflect.Value) (bool, error) {
+	s := val.String()
+	if !strings.Contains(s, "${") {
+		return false, nil
+	}
+	var (
+		found    bool
+		firstErr error
+	)
+	out := secretRefPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := secretRefPattern.FindStringSubmatch(m)
+		r, ok := v.secretResolvers[sub[1]]
+		if !ok || firstErr != nil {
+			return m
+		}
+		found = true
+		resolved, err := r.Resolve(ctx, sub[2])
+		if err != nil {
+			firstErr = fmt.Errorf("%s reference: %w", sub[1], err)
+			return m
+		}
+		return resolved
+	})
+	if firstErr != nil {
+		return found, firstErr
+	}
+	if found && val.CanSet() {
+		val.SetString(out)
+	}
+	return found, nil
+}
+
+// SecretKeys returns the keys whose value came from a secret reference during the
+// last Load, sorted. Anything dumping the config should mask these.
+func (v *ViperLoader) SecretKeys() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.secretKeys))
+	for k := range v.secretKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
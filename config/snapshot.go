@@ -0,0 +1,97 @@
+package config_load
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// WithConsulSnapshot persists every successful remote payload (Consul or any other
+// Source) to path and uses it as a fallback layer when the source cannot be reached
+// at boot, so remote-only keys survive an outage: remote -> snapshot -> file -> env.
+// The file is written atomically (temp file + rename); write failures are reported to
+// WithErrorHandler and never fail Load.
+func WithConsulSnapshot(path string) Option {
+	return func(v *ViperLoader) {
+		v.snapshotPath = path
+	}
+}
+
+// SnapshotAge reports how old the remote snapshot used by the last load is. ok is false
+// when the last load did not fall back to a snapshot. Use it in readiness checks to
+// flag config that has been stale for too long.
+func (v *ViperLoader) SnapshotAge() (age time.Duration, ok bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.usedSnapshot {
+		return 0, false
+	}
+	return time.Since(v.snapshotTime), true
+}
+
+func (v *ViperLoader) writeSnapshot(payload []byte) error {
+	if v.snapshotPath == "" {
+		return nil
+	}
+	dir := filepath.Dir(v.snapshotPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("config: write snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(v.snapshotPath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("config: write snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(payload); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("config: write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("config: write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), v.snapshotPath); err != nil {
+		return fmt.Errorf("config: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// mergeSnapshot merges the snapshot over whatever file layer was read. It only applies
+// when a remote source is configured; a missing snapshot is not an error.
+func (v *ViperLoader) mergeSnapshot() (bool, error) {
+	v.usedSnapshot = false
+	src := v.remoteSource()
+	if v.snapshotPath == "" || src == nil {
+		return false, nil
+	}
+	info, err := os.Stat(v.snapshotPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("config: read snapshot: %w", err)
+	}
+	payload, err := os.ReadFile(v.snapshotPath)
+	if err != nil {
+		return false, fmt.Errorf("config: read snapshot: %w", err)
+	}
+	// Parse on a separate instance: the snapshot has the source's format, whatever
+	// format the config file uses.
+	tmp := viper.New()
+	tmp.SetConfigType(src.ConfigType())
+	if err := tmp.ReadConfig(bytes.NewReader(payload)); err != nil {
+		return false, fmt.Errorf("config: parse snapshot %s: %w", v.snapshotPath, err)
+	}
+	if err := v.MergeConfigMap(tmp.AllSettings()); err != nil {
+		return false, fmt.Errorf("config: merge snapshot: %w", err)
+	}
+	for _, key := range tmp.AllKeys() {
+		v.keyOrigins[key] = keyOrigin{layer: LayerSnapshot, path: v.snapshotPath}
+	}
+	v.usedSnapshot = true
+	v.snapshotTime = info.ModTime()
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package config_load
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type snapshotConfig struct {
+	App struct {
+		Name string `mapstructure:"name"`
+	} `mapstructure:"app"`
+	Feature struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"feature"`
+	DB struct {
+		Host string `mapstructure:"host"`
+	} `mapstructure:"db"`
+}
+
+func TestLoad_ConsulSnapshotFallback(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: from-consul\nfeature:\n  enabled: true\n")
+	dir := t.TempDir()
+	snap := filepath.Join(dir, "snap", "config.yaml")
+
+	// 1) Consul is up: the payload is loaded and persisted.
+	loader := New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+		WithConsulSnapshot(snap),
+	)
+	var cfg snapshotConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := loader.SnapshotAge(); ok {
+		t.Fatal("SnapshotAge must report false when Consul answered")
+	}
+	if _, err := os.Stat(snap); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+
+	// 2) Consul is down: snapshot sits between Consul and the local file, env on top.
+	fc.setDown(true)
+	filesDir := t.TempDir()
+	writeTempYAML(t, filesDir, "config.yaml", "app:\n  name: from-file\ndb:\n  host: file-host\n")
+	t.Setenv("APP_DB_HOST", "env-host")
+
+	loader = New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+		WithConfigFileSearchPaths(filesDir),
+		WithConsulSnapshot(snap),
+	)
+	cfg = snapshotConfig{}
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "from-consul" || !cfg.Feature.Enabled {
+		t.Fatalf("snapshot keys must win over file: %+v", cfg)
+	}
+	if cfg.DB.Host != "env-host" {
+		t.Fatalf("env must still win over snapshot and file, got %q", cfg.DB.Host)
+	}
+	if age, ok := loader.SnapshotAge(); !ok || age < 0 {
+		t.Fatalf("SnapshotAge = %v, %v", age, ok)
+	}
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range exp.Keys {
+		if k.Key == "feature.enabled" && k.Layer != LayerSnapshot {
+			t.Fatalf("feature.enabled layer = %s, want snapshot", k.Layer)
+		}
+	}
+}
+
+func TestLoad_ConsulSnapshotWithoutFile(t *testing.T) {
+	_, addr := newFakeConsul(t, "svc/config", "")
+	dir := t.TempDir()
+	snap := writeTempYAML(t, dir, "snapshot.yaml", "app:\n  name: cached\n")
+
+	loader := New("APP", "other/key", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+		WithConfigFileSearchPaths(t.TempDir()),
+		WithConsulSnapshot(snap),
+	)
+	var cfg snapshotConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("snapshot alone must be enough to boot, got: %v", err)
+	}
+	if cfg.App.Name != "cached" {
+		t.Fatalf("expected cached, got %q", cfg.App.Name)
+	}
+
+	// Without a snapshot the missing file is still an error.
+	loader = New("APP", "other/key", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithConfigFileSearchPaths(t.TempDir()),
+		WithConsulSnapshot(filepath.Join(dir, "absent.yaml")),
+	)
+	if err := loader.Load(&cfg); !errors.Is(err, ErrConfigFileNotFound) {
+		t.Fatalf("expected ErrConfigFileNotFound, got %v", err)
+	}
+}
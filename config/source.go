@@ -0,0 +1,111 @@
+package config_load
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Source is a remote config provider. The loader reads a Source before file and env
+// (see Load for the fallback rules) and Watch subscribes to it for hot reload.
+//
+// Built-ins: NewConsulSource, NewEtcdSource and NewHTTPSource. New wires a Consul
+// source from its consulKey/consulURL arguments; WithSource selects any other.
+type Source interface {
+	// Name identifies the source in errors and Explain, e.g. "consul://127.0.0.1:8500/app/config".
+	Name() string
+	// ConfigType is the payload format handed to viper: "yaml", "json" or "toml".
+	ConfigType() string
+	// Fetch returns the current payload.
+	Fetch(ctx context.Context) ([]byte, error)
+	// Watch blocks until ctx is done, calling onChange with the payload whenever it may
+	// have changed. Transient failures are passed as err with a nil payload and Watch
+	// keeps going; it returns ctx.Err() or an error it cannot recover from.
+	Watch(ctx context.Context, onChange func(payload []byte, err error)) error
+}
+
+// WithSource makes the loader read from src instead of the Consul key given to New.
+func WithSource(src Source) Option {
+	return func(v *ViperLoader) {
+		v.source = src
+	}
+}
+
+// SourceOption configures a built-in Source.
+type SourceOption func(*sourceOptions)
+
+type sourceOptions struct {
+	configType   string
+	pollInterval time.Duration
+	timeout      time.Duration
+	httpClient   *http.Client
+	header       http.Header
+}
+
+func newSourceOptions(defaultType string, opts []SourceOption) sourceOptions {
+	o := sourceOptions{
+		configType:   defaultType,
+		pollInterval: 30 * time.Second,
+		timeout:      5 * time.Second,
+		header:       http.Header{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSourceConfigType sets the payload format: "yaml", "json" or "toml".
+func WithSourceConfigType(configType string) SourceOption {
+	return func(o *sourceOptions) {
+		if configType != "" {
+			o.configType = configType
+		}
+	}
+}
+
+// WithSourcePollInterval sets how often a polling source checks for changes, and how
+// long a watching source waits before retrying after an error (default 30s).
+func WithSourcePollInterval(d time.Duration) SourceOption {
+	return func(o *sourceOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithSourceTimeout bounds connection setup for etcd and each request of the HTTP
+// source (default 5s).
+func WithSourceTimeout(d time.Duration) SourceOption {
+	return func(o *sourceOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithSourceHTTPClient sets the client used by the Consul and HTTP sources.
+func WithSourceHTTPClient(c *http.Client) SourceOption {
+	return func(o *sourceOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithSourceHeader adds a request header to the HTTP source, e.g. an auth token.
+func WithSourceHeader(key, value string) SourceOption {
+	return func(o *sourceOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package config_load
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+type consulSource struct {
+	address string
+	key     string
+	opts    sourceOptions
+}
+
+// NewConsulSource reads a single Consul KV key (YAML unless WithSourceConfigType says
+// otherwise). Watch uses Consul blocking queries, so changes are seen immediately.
+func NewConsulSource(address, key string, opts ...SourceOption) Source {
+	return &consulSource{
+		address: address,
+		key:     strings.TrimPrefix(key, "/"),
+		opts:    newSourceOptions("yaml", opts),
+	}
+}
+
+func (s *consulSource) Name() string       { return "consul://" + s.address + "/" + s.key }
+func (s *consulSource) ConfigType() string { return s.opts.configType }
+
+func (s *consulSource) Fetch(ctx context.Context) ([]byte, error) {
+	payload, _, err := s.get(ctx, 0)
+	return payload, err
+}
+
+func (s *consulSource) Watch(ctx context.Context, onChange func(payload []byte, err error)) error {
+	var index uint64
+	for {
+		start := time.Now()
+		payload, next, err := s.get(ctx, index)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			onChange(nil, err)
+			if err := sleepContext(ctx, s.opts.pollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		changed := index == 0 || next != index
+		if changed {
+			onChange(payload, nil)
+		}
+		// Consul asks clients to reset when the index goes backwards.
+		if next < index {
+			next = 0
+		}
+		index = next
+		// Do not spin against an agent or proxy that answers blocking queries at once.
+		if !changed && time.Since(start) < s.opts.pollInterval {
+			if err := sleepContext(ctx, s.opts.pollInterval-time.Since(start)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// get reads the key, blocking until it changes past waitIndex when waitIndex > 0.
+func (s *consulSource) get(ctx context.Context, waitIndex uint64) ([]byte, uint64, error) {
+	conf := api.DefaultConfig()
+	conf.Address = s.address
+	if s.opts.httpClient != nil {
+		conf.HttpClient = s.opts.httpClient
+	}
+	client, err := api.NewClient(conf)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := (&api.QueryOptions{WaitIndex: waitIndex}).WithContext(ctx)
+	pair, meta, err := client.KV().Get(s.key, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pair == nil {
+		return nil, 0, fmt.Errorf("consul key %q not found", s.key)
+	}
+	return pair.Value, meta.LastIndex, nil
+}
@@ -0,0 +1,108 @@
+package config_load
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type httpSource struct {
+	url  string
+	opts sourceOptions
+}
+
+// NewHTTPSource reads a config document from a plain HTTP(S) endpoint. The format is
+// taken from WithSourceConfigType, else from the URL extension (.yaml, .yml, .json,
+// .toml), else JSON. Watch polls every WithSourcePollInterval and honours ETags.
+func NewHTTPSource(rawURL string, opts ...SourceOption) Source {
+	s := &httpSource{url: rawURL, opts: newSourceOptions("", opts)}
+	if s.opts.configType == "" {
+		s.opts.configType = typeFromURL(rawURL)
+	}
+	if s.opts.httpClient == nil {
+		s.opts.httpClient = &http.Client{Timeout: s.opts.timeout}
+	}
+	return s
+}
+
+func typeFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "json"
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+func (s *httpSource) Name() string       { return s.url }
+func (s *httpSource) ConfigType() string { return s.opts.configType }
+
+func (s *httpSource) Fetch(ctx context.Context) ([]byte, error) {
+	payload, _, _, err := s.get(ctx, "")
+	return payload, err
+}
+
+func (s *httpSource) Watch(ctx context.Context, onChange func(payload []byte, err error)) error {
+	var (
+		etag string
+		last []byte
+	)
+	for {
+		payload, tag, notModified, err := s.get(ctx, etag)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			onChange(nil, err)
+		case notModified:
+		case last == nil || !bytes.Equal(payload, last):
+			last, etag = payload, tag
+			onChange(payload, nil)
+		}
+		if err := sleepContext(ctx, s.opts.pollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *httpSource) get(ctx context.Context, etag string) (payload []byte, newETag string, notModified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	for k, vs := range s.opts.header {
+		for _, val := range vs {
+			req.Header.Add(k, val)
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := s.opts.httpClient.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, true, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", false, fmt.Errorf("GET %s: unexpected status %s", s.url, resp.Status)
+	}
+	payload, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return payload, resp.Header.Get("ETag"), false, nil
+}
@@ -0,0 +1,311 @@
+package config_load
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"google.golang.org/grpc"
+)
+
+// fakeEtcd implements the KV Range and Watch RPCs for a single key.
+type fakeEtcd struct {
+	pb.UnimplementedKVServer
+	pb.UnimplementedWatchServer
+
+	mu       sync.Mutex
+	key      string
+	value    string
+	rev      int64
+	watchers []chan *mvccpb.KeyValue
+}
+
+func newFakeEtcd(t *testing.T, key, value string) (*fakeEtcd, string) {
+	t.Helper()
+	fe := &fakeEtcd{key: key, value: value, rev: 1}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterKVServer(srv, fe)
+	pb.RegisterWatchServer(srv, fe)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return fe, lis.Addr().String()
+}
+
+func (fe *fakeEtcd) kv() *mvccpb.KeyValue {
+	return &mvccpb.KeyValue{Key: []byte(fe.key), Value: []byte(fe.value), ModRevision: fe.rev}
+}
+
+func (fe *fakeEtcd) set(value string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	fe.value = value
+	fe.rev++
+	for _, ch := range fe.watchers {
+		ch <- fe.kv()
+	}
+}
+
+func (fe *fakeEtcd) Range(_ context.Context, req *pb.RangeRequest) (*pb.RangeResponse, error) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	resp := &pb.RangeResponse{Header: &pb.ResponseHeader{Revision: fe.rev}}
+	if string(req.Key) == fe.key {
+		resp.Kvs = []*mvccpb.KeyValue{fe.kv()}
+		resp.Count = 1
+	}
+	return resp, nil
+}
+
+func (fe *fakeEtcd) Watch(stream pb.Watch_WatchServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	ch := make(chan *mvccpb.KeyValue, 8)
+	fe.mu.Lock()
+	fe.watchers = append(fe.watchers, ch)
+	rev := fe.rev
+	fe.mu.Unlock()
+	if err := stream.Send(&pb.WatchResponse{Header: &pb.ResponseHeader{Revision: rev}, Created: true}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case kv := <-ch:
+			err := stream.Send(&pb.WatchResponse{
+				Header: &pb.ResponseHeader{Revision: kv.ModRevision},
+				Events: []*mvccpb.Event{{Type: mvccpb.PUT, Kv: kv}},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchSource loads through src with Watch and returns a channel of App.Name values
+// seen by subscribers.
+func watchSource(t *testing.T, ctx context.Context, src Source) (*appConfig, <-chan string) {
+	t.Helper()
+	changed := make(chan string, 4)
+	loader := New("APP", "", "",
+		WithSource(src),
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+	)
+	var cfg appConfig
+	_, err := loader.Watch(ctx, &cfg, func(_, new interface{}) {
+		changed <- new.(*appConfig).App.Name
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &cfg, changed
+}
+
+func expectChange(t *testing.T, changed <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-changed:
+		if got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("change to %q was not picked up", want)
+	}
+}
+
+func TestConsulSource_FetchAndWatch(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: v1\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewConsulSource(addr, "svc/config", WithSourcePollInterval(20*time.Millisecond))
+	cfg, changed := watchSource(t, ctx, src)
+	if cfg.App.Name != "v1" {
+		t.Fatalf("expected App.Name=v1, got %q", cfg.App.Name)
+	}
+	fc.set("app:\n  name: v2\n")
+	expectChange(t, changed, "v2")
+}
+
+func TestEtcdSource_FetchAndWatch(t *testing.T) {
+	fe, addr := newFakeEtcd(t, "/svc/config", "app:\n  name: v1\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewEtcdSource([]string{addr}, "/svc/config")
+	if got := src.Name(); got != "etcd://"+addr+"/svc/config" {
+		t.Fatalf("unexpected name %q", got)
+	}
+	cfg, changed := watchSource(t, ctx, src)
+	if cfg.App.Name != "v1" {
+		t.Fatalf("expected App.Name=v1, got %q", cfg.App.Name)
+	}
+	fe.set("app:\n  name: v2\n")
+	expectChange(t, changed, "v2")
+}
+
+func TestHTTPSource_JSONWithETag(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		body        = `{"app":{"name":"v1"},"database":{"port":5432}}`
+		etag        = `"1"`
+		notModified atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0k" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewHTTPSource(srv.URL+"/config.json",
+		WithSourceHeader("Authorization", "Bearer t0k"),
+		WithSourcePollInterval(20*time.Millisecond),
+	)
+	if src.ConfigType() != "json" {
+		t.Fatalf("expected json config type, got %q", src.ConfigType())
+	}
+	cfg, changed := watchSource(t, ctx, src)
+	if cfg.App.Name != "v1" || cfg.Database.Port != 5432 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	waitFor(t, "conditional request", func() bool { return notModified.Load() > 0 })
+
+	mu.Lock()
+	body, etag = `{"app":{"name":"v2"},"database":{"port":5432}}`, `"2"`
+	mu.Unlock()
+	expectChange(t, changed, "v2")
+}
+
+func TestHTTPSource_ConfigTypeFromURL(t *testing.T) {
+	cases := map[string]string{
+		"http://cfg/app.yaml":         "yaml",
+		"http://cfg/app.YML?rev=3":    "yaml",
+		"http://cfg/app.toml":         "toml",
+		"http://cfg/v1/config":        "json",
+		"http://cfg/app.yaml#section": "yaml",
+	}
+	for url, want := range cases {
+		if got := NewHTTPSource(url).ConfigType(); got != want {
+			t.Errorf("%s: expected %s, got %s", url, want, got)
+		}
+	}
+	if got := NewHTTPSource("http://cfg/app.json", WithSourceConfigType("yaml")).ConfigType(); got != "yaml" {
+		t.Errorf("explicit config type must win, got %s", got)
+	}
+}
+
+func TestLoad_SourceFailureFallsBackToFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: from-file\n")
+
+	var handled error
+	loader := New("APP", "", "",
+		WithSource(NewHTTPSource(srv.URL)),
+		WithLoadFromConsulMaxAttempt(2),
+		WithRemoteBackoff(time.Millisecond, time.Millisecond),
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithErrorHandler(func(err error) { handled = err }),
+	)
+	var cfg appConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "from-file" {
+		t.Fatalf("expected fallback to file, got %q", cfg.App.Name)
+	}
+	var rerr *RemoteLoadError
+	if !errors.As(handled, &rerr) || rerr.Source != srv.URL || len(rerr.Attempts) != 2 {
+		t.Fatalf("expected RemoteLoadError for %s with 2 attempts, got %v", srv.URL, handled)
+	}
+
+	exp, err := loader.Explain(&cfg)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	for _, k := range exp.Keys {
+		if k.Key == "app.name" && k.Layer != LayerFile {
+			t.Fatalf("expected app.name from file, got %s", k.Layer)
+		}
+	}
+}
+
+func TestLoad_JSONSourceThenYAMLFallback(t *testing.T) {
+	var (
+		down atomic.Bool
+		body atomic.Value
+	)
+	body.Store(`{"app":{"name":"from-json"},"database":{"port":5432}}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "boom", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: from-file\n")
+
+	loader := New("APP", "", "",
+		WithSource(NewHTTPSource(srv.URL+"/config.json")),
+		WithLoadFromConsulMaxAttempt(1),
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithErrorHandler(func(error) {}),
+	)
+	var cfg appConfig
+	if err := loader.Load(&cfg); err != nil || cfg.App.Name != "from-json" || cfg.Database.Port != 5432 {
+		t.Fatalf("remote load: %+v, %v", cfg, err)
+	}
+
+	// A key deleted at the source is gone on the next load.
+	body.Store(`{"app":{"name":"from-json"}}`)
+	cfg = appConfig{}
+	if err := loader.Load(&cfg); err != nil || cfg.Database.Port != 0 {
+		t.Fatalf("deleted key lingered: %+v, %v", cfg, err)
+	}
+
+	// The JSON payload must not leave its format on the loader for the YAML file.
+	down.Store(true)
+	cfg = appConfig{}
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("fallback after a JSON source: %v", err)
+	}
+	if cfg.App.Name != "from-file" {
+		t.Fatalf("expected fallback to file, got %q", cfg.App.Name)
+	}
+}
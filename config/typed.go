@@ -0,0 +1,73 @@
+package config_load
+
+import (
+	"context"
+	"fmt"
+)
+
+// LoadOption configures a single LoadAs or MustLoad call.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	ctx context.Context
+}
+
+// WithLoadContext bounds the load with ctx, like LoadContext.
+func WithLoadContext(ctx context.Context) LoadOption {
+	return func(o *loadOptions) {
+		if ctx != nil {
+			o.ctx = ctx
+		}
+	}
+}
+
+// LoadAs loads the config into a new T and returns it. T is the struct type itself,
+// not a pointer to it:
+//
+//	cfg, err := config_load.LoadAs[AppConfig](loader)
+func LoadAs[T any](loader *ViperLoader, opts ...LoadOption) (T, error) {
+	o := loadOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var cfg T
+	err := loader.LoadContext(o.ctx, &cfg)
+	return cfg, err
+}
+
+// MustLoad is LoadAs that panics on error, for use in main.
+func MustLoad[T any](loader *ViperLoader, opts ...LoadOption) T {
+	cfg, err := LoadAs[T](loader, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("config: load %T: %v", cfg, err))
+	}
+	return cfg
+}
+
+// Store holds the latest config of type T and keeps it up to date through Watch.
+// Get is a single atomic load, so it is safe to call on every request.
+type Store[T any] struct {
+	w *Watcher
+}
+
+// NewStore loads a T and watches its source until ctx is done, like ViperLoader.Watch.
+func NewStore[T any](ctx context.Context, loader *ViperLoader) (*Store[T], error) {
+	w, err := loader.Watch(ctx, new(T), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Store[T]{w: w}, nil
+}
+
+// Get returns the latest config. The value is shared and must not be modified.
+func (s *Store[T]) Get() *T {
+	return Current[T](s.w)
+}
+
+// OnChange registers fn to be called after every effective reload.
+func (s *Store[T]) OnChange(fn func(old, new *T)) {
+	OnChange(s.w, fn)
+}
+
+// Done is closed once the store stopped watching after ctx was cancelled.
+func (s *Store[T]) Done() <-chan struct{} { return s.w.Done() }
@@ -0,0 +1,76 @@
+package config_load
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAs_ReturnsStruct(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: typed\ndatabase:\n  port: 5432\n")
+
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	cfg, err := LoadAs[appConfig](loader, WithLoadContext(context.Background()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "typed" || cfg.Database.Port != 5432 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadAs_ContextCancelled(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "")
+	fc.setDown(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	loader := New("APP", "svc/config", addr, WithStructTagName("mapstructure"))
+	if _, err := LoadAs[appConfig](loader, WithLoadContext(ctx)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMustLoad_PanicsOnError(t *testing.T) {
+	loader := New("APP", "", "", WithConfigFileSearchPaths(t.TempDir()))
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "config file not found") {
+			t.Fatalf("expected panic with load error, got %v", r)
+		}
+	}()
+	MustLoad[appConfig](loader)
+}
+
+func TestStore_GetFollowsReload(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: v1\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loader := New("APP", "", "",
+		WithSource(NewConsulSource(addr, "svc/config", WithSourcePollInterval(20*time.Millisecond))),
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+	)
+	store, err := NewStore[appConfig](ctx, loader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := store.Get().App.Name; got != "v1" {
+		t.Fatalf("expected v1, got %q", got)
+	}
+	changed := make(chan string, 1)
+	store.OnChange(func(_, new *appConfig) { changed <- new.App.Name })
+
+	fc.set("app:\n  name: v2\n")
+	expectChange(t, changed, "v2")
+	if got := store.Get().App.Name; got != "v2" {
+		t.Fatalf("expected Get to return v2, got %q", got)
+	}
+
+	cancel()
+	<-store.Done()
+}
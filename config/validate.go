@@ -0,0 +1,168 @@
+package config_load
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a single field that failed its `validate` tag.
+type FieldError struct {
+	Path string // key path as the operator writes it, e.g. "db.pool.max"
+	Rule string // failing rule as written in the tag, e.g. "min=1"
+	Msg  string // human readable reason
+}
+
+func (e FieldError) String() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// ValidationError aggregates every field that failed validation after Load.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// validate evaluates the `validate` tags of cfg. Supported rules, comma separated:
+//
+//	required     value must not be the zero value
+//	min=N, max=N numbers are bounded by value; strings, slices and maps by length
+//	oneof=a b c  value must equal one of the space separated options
+//	url          string must be an absolute URL with a scheme and host
+//	duration     string must parse with time.ParseDuration
+//
+// oneof, url and duration skip empty values so optional fields only need
+// `required` when they must be present.
+func validate(cfg interface{}, tagName string) error {
+	root := reflect.ValueOf(cfg).Elem()
+	var failed []FieldError
+	for _, f := range structFields(root.Type(), tagName) {
+		tag := f.sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		val, ok := fieldValue(root, f.index)
+		for _, rule := range strings.Split(tag, ",") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+			var msg string
+			if ok {
+				msg = checkRule(val, rule)
+			} else if rule == "required" {
+				msg = "is required"
+			}
+			if msg != "" {
+				failed = append(failed, FieldError{Path: f.path, Rule: rule, Msg: msg})
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return &ValidationError{Fields: failed}
+	}
+	return nil
+}
+
+// checkRule returns a failure message, or "" when v satisfies rule.
+func checkRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Pointer && name != "required" {
+		return ""
+	}
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %q", rule)
+		}
+		n, isLen, ok := measure(v)
+		if !ok {
+			return fmt.Sprintf("rule %q does not apply to %s", rule, v.Type())
+		}
+		what := "must be"
+		if isLen {
+			what = "length must be"
+		}
+		if name == "min" && n < bound {
+			return fmt.Sprintf("%s >= %s", what, arg)
+		}
+		if name == "max" && n > bound {
+			return fmt.Sprintf("%s <= %s", what, arg)
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		if s == "" {
+			return ""
+		}
+		for _, opt := range strings.Fields(arg) {
+			if s == opt {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", arg)
+	case "url":
+		s, ok := stringOf(v)
+		if !ok || s == "" {
+			return ""
+		}
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URL"
+		}
+	case "duration":
+		s, ok := stringOf(v)
+		if !ok || s == "" {
+			return ""
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return "must be a duration such as 30s or 5m"
+		}
+	default:
+		return fmt.Sprintf("unknown rule %q", rule)
+	}
+	return ""
+}
+
+// measure returns the number min/max compare against: the value for numbers,
+// the length for strings, slices and maps.
+func measure(v reflect.Value) (n float64, isLen, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true, true
+	}
+	return 0, false, false
+}
+
+func stringOf(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
@@ -0,0 +1,77 @@
+package config_load
+
+import (
+	"errors"
+	"testing"
+)
+
+type validatedConfig struct {
+	Env string `mapstructure:"env" validate:"required,oneof=dev staging prod"`
+	DB  struct {
+		DSN  string `mapstructure:"dsn" validate:"required,url"`
+		Pool struct {
+			Max int `mapstructure:"max" validate:"min=1,max=100"`
+		} `mapstructure:"pool"`
+		Timeout string `mapstructure:"timeout" validate:"duration"`
+	} `mapstructure:"db"`
+	Brokers []string `mapstructure:"brokers" validate:"min=1"`
+}
+
+func TestLoad_ValidationAggregatesEveryField(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", `
+env: qa
+db:
+  dsn: "not a url"
+  pool:
+    max: -1
+  timeout: soon
+`)
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg validatedConfig
+	err := loader.Load(&cfg)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got: %v", err)
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	got := map[string]string{}
+	for _, f := range verr.Fields {
+		got[f.Path] = f.Rule
+	}
+	want := map[string]string{
+		"env":         "oneof=dev staging prod",
+		"db.dsn":      "url",
+		"db.pool.max": "min=1",
+		"db.timeout":  "duration",
+		"brokers":     "min=1",
+	}
+	for path, rule := range want {
+		if got[path] != rule {
+			t.Errorf("field %s: expected rule %q, got %q (all: %v)", path, rule, got[path], verr.Fields)
+		}
+	}
+	if len(verr.Fields) != len(want) {
+		t.Errorf("expected %d failures, got %d: %v", len(want), len(verr.Fields), verr.Fields)
+	}
+}
+
+func TestLoad_ValidationPasses(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", `
+env: prod
+db:
+  dsn: "postgres://db.internal:5432/app"
+  pool:
+    max: 10
+  timeout: 5s
+brokers: ["kafka-1:9092"]
+`)
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg validatedConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package config_load
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ChangeFunc is called after a reload with the previous and the freshly decoded config.
+// Both values are pointers of the same type as the cfg passed to Watch.
+type ChangeFunc func(old, new interface{})
+
+// Watcher holds the latest config decoded by ViperLoader.Watch. Each reload decodes
+// into a fresh copy of the struct and swaps it in atomically, so a value returned
+// by Current is never mutated afterwards.
+type Watcher struct {
+	loader  *ViperLoader
+	typ     reflect.Type
+	current atomic.Value // holds cfgBox
+	subsMu  sync.Mutex
+	subs    []ChangeFunc
+	done    chan struct{}
+	payload []byte // remote payload this watcher last decoded; owned by watchRemote
+}
+
+// cfgBox keeps the concrete type stored in atomic.Value consistent across swaps.
+type cfgBox struct{ cfg interface{} }
+
+// Watch loads cfg like LoadContext, then keeps re-reading the source it was loaded from until
+// ctx is done: the config files (base and overlays) through fsnotify, or the remote
+// Source through its Watch method. Reloads go through the same read and decode path as Load.
+//
+// cfg itself is only written by the initial load; use Current (or the typed Current
+// helper) to read the latest value. A reload that fails to read or decode keeps the
+// previous config and is reported to WithErrorHandler. Subscribers are only called
+// when the decoded value actually changed.
+func (v *ViperLoader) Watch(ctx context.Context, cfg interface{}, onChange ChangeFunc) (*Watcher, error) {
+	if err := v.LoadContext(ctx, cfg); err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		loader: v,
+		typ:    reflect.TypeOf(cfg).Elem(),
+		done:   make(chan struct{}),
+	}
+	w.current.Store(cfgBox{cfg: cfg})
+	if onChange != nil {
+		w.subs = append(w.subs, onChange)
+	}
+
+	v.mu.Lock()
+	fromRemote := v.fromRemote
+	src := v.source
+	w.payload = v.remotePayload
+	files := append([]string(nil), v.configFiles...)
+	v.mu.Unlock()
+
+	if fromRemote {
+		go w.watchRemote(ctx, src)
+		return w, nil
+	}
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("config: create watcher: %w", err)
+	}
+	// Watch the directories rather than the files: editors replace a file via rename,
+	// which drops a watch placed on the file itself. A Kubernetes ConfigMap update never
+	// touches the file either; it swaps the ..data symlink the file resolves through, so
+	// each file's symlink target is tracked as well.
+	watched := make(map[string]string, len(files))
+	for _, file := range files {
+		file = filepath.Clean(file)
+		watched[file] = resolveSymlinks(file)
+		if err := fw.Add(filepath.Dir(file)); err != nil {
+			_ = fw.Close()
+			return nil, fmt.Errorf("config: watch %s: %w", file, err)
+		}
+	}
+	go w.watchFiles(ctx, fw, watched)
+	return w, nil
+}
+
+// Current returns the latest config, a pointer of the same type passed to Watch.
+func (w *Watcher) Current() interface{} {
+	return w.current.Load().(cfgBox).cfg
+}
+
+// Subscribe registers fn to be called after every effective reload.
+func (w *Watcher) Subscribe(fn ChangeFunc) {
+	if fn == nil {
+		return
+	}
+	w.subsMu.Lock()
+	w.subs = append(w.subs, fn)
+	w.subsMu.Unlock()
+}
+
+// Done is closed once the watch loop has exited after ctx was cancelled.
+func (w *Watcher) Done() <-chan struct{} { return w.done }
+
+// Current returns the latest config held by w as *T. It panics if T is not the
+// type passed to Watch.
+func Current[T any](w *Watcher) *T {
+	return w.Current().(*T)
+}
+
+// OnChange subscribes a typed callback to w. It panics if T is not the type passed to Watch.
+func OnChange[T any](w *Watcher, fn func(old, new *T)) {
+	if reflect.TypeOf((*T)(nil)).Elem() != w.typ {
+		panic(fmt.Sprintf("config: OnChange type %T does not match watched type %s", (*T)(nil), w.typ))
+	}
+	w.Subscribe(func(old, new interface{}) {
+		fn(old.(*T), new.(*T))
+	})
+}
+
+// reloadDebounce coalesces the burst of events a single save produces (truncate,
+// write, chmod) so the file is not decoded half-written.
+const reloadDebounce = 100 * time.Millisecond
+
+// watchFiles reloads when a watched file is written or replaced, or when any change in
+// its directory moves the file's symlink target. files maps each file to its last
+// resolved target.
+func (w *Watcher) watchFiles(ctx context.Context, fw *fsnotify.Watcher, files map[string]string) {
+	defer close(w.done)
+	defer fw.Close()
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if retargeted(files, filepath.Clean(ev.Name)) {
+				debounce.Reset(reloadDebounce)
+			}
+		case <-debounce.C:
+			w.reload(ctx, w.loader.loadFallback)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			w.loader.handleError(fmt.Errorf("config: watch: %w", err))
+		}
+	}
+}
+
+// retargeted reports whether the event on name concerns a watched file: name is the
+// file itself, or a sibling change (such as a ConfigMap's ..data swap) moved where the
+// file's symlinks resolve to. The new targets are recorded in files.
+func retargeted(files map[string]string, name string) bool {
+	_, changed := files[name]
+	dir := filepath.Dir(name)
+	for file, target := range files {
+		if filepath.Dir(file) != dir {
+			continue
+		}
+		if now := resolveSymlinks(file); now != target {
+			files[file] = now
+			changed = true
+		}
+	}
+	return changed
+}
+
+// resolveSymlinks returns the real path of file, or "" while it cannot be resolved
+// (e.g. mid-swap).
+func resolveSymlinks(file string) string {
+	real, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return ""
+	}
+	return real
+}
+
+func (w *Watcher) watchRemote(ctx context.Context, src Source) {
+	defer close(w.done)
+	err := src.Watch(ctx, func(payload []byte, err error) {
+		if err != nil {
+			w.loader.handleError(fmt.Errorf("config: watch %s: %w", src.Name(), err))
+			return
+		}
+		w.reload(ctx, func() error {
+			// Several watchers may share the loader, each running its own Source.Watch:
+			// compare against what this one decoded last, and skip re-applying a payload
+			// another watcher already installed.
+			if bytes.Equal(payload, w.payload) {
+				return errUnchanged
+			}
+			if !bytes.Equal(payload, w.loader.remotePayload) {
+				if err := w.loader.applyRemote(src, payload); err != nil {
+					return err
+				}
+			}
+			w.payload = payload
+			return nil
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		w.loader.handleError(fmt.Errorf("config: watch %s stopped: %w", src.Name(), err))
+	}
+}
+
+var errUnchanged = fmt.Errorf("config unchanged")
+
+// reload refreshes the viper state with read, decodes into a fresh struct and swaps
+// it in. On failure the previous config stays active.
+func (w *Watcher) reload(ctx context.Context, read func() error) {
+	v := w.loader
+	next := reflect.New(w.typ).Interface()
+
+	v.mu.Lock()
+	err := read()
+	if err == nil {
+		err = v.decode(ctx, next)
+	}
+	v.mu.Unlock()
+	if err == errUnchanged {
+		return
+	}
+	if err != nil {
+		v.handleError(fmt.Errorf("config: reload: %w", err))
+		return
+	}
+
+	prev := w.Current()
+	if reflect.DeepEqual(prev, next) {
+		return
+	}
+	w.current.Store(cfgBox{cfg: next})
+
+	w.subsMu.Lock()
+	subs := append([]ChangeFunc(nil), w.subs...)
+	w.subsMu.Unlock()
+	for _, fn := range subs {
+		fn(prev, next)
+	}
+}
+
+func (v *ViperLoader) handleError(err error) {
+	if v.errorHandler != nil {
+		v.errorHandler(err)
+	}
+}
@@ -0,0 +1,244 @@
+package config_load
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeConsul serves a single KV key the way Consul's /v1/kv endpoint does.
+type fakeConsul struct {
+	mu    sync.Mutex
+	key   string
+	value string
+	index int // X-Consul-Index, bumped on every set like a real ModifyIndex
+	down  bool
+}
+
+func newFakeConsul(t *testing.T, key, value string) (*fakeConsul, string) {
+	t.Helper()
+	fc := &fakeConsul{key: key, value: value, index: 1}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		if fc.down {
+			http.Error(w, "consul unavailable", http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path != "/v1/kv/"+fc.key {
+			w.Header().Set("X-Consul-Index", "1")
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Consul-Index", strconv.Itoa(fc.index))
+		fmt.Fprintf(w, `[{"Key":%q,"Value":%q}]`, fc.key, base64.StdEncoding.EncodeToString([]byte(fc.value)))
+	}))
+	t.Cleanup(srv.Close)
+	return fc, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func (fc *fakeConsul) set(value string) {
+	fc.mu.Lock()
+	fc.value = value
+	fc.index++
+	fc.mu.Unlock()
+}
+
+func (fc *fakeConsul) setDown(down bool) {
+	fc.mu.Lock()
+	fc.down = down
+	fc.mu.Unlock()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestWatch_FileChangeSwapsConfigAndNotifies(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: first\ndatabase:\n  port: 1\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var notified atomic.Int32
+	var seenOld, seenNew atomic.Value
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg appConfig
+	w, err := loader.Watch(ctx, &cfg, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	OnChange(w, func(old, new *appConfig) {
+		seenOld.Store(old.App.Name)
+		seenNew.Store(new.App.Name)
+		notified.Add(1)
+	})
+	if cfg.App.Name != "first" || Current[appConfig](w).App.Name != "first" {
+		t.Fatalf("initial load: cfg=%q current=%q", cfg.App.Name, Current[appConfig](w).App.Name)
+	}
+
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: second\ndatabase:\n  port: 2\n")
+	waitFor(t, "reload", func() bool { return Current[appConfig](w).App.Name == "second" })
+	waitFor(t, "notification", func() bool { return notified.Load() > 0 })
+
+	if seenOld.Load() != "first" || seenNew.Load() != "second" {
+		t.Fatalf("subscriber saw old=%v new=%v", seenOld.Load(), seenNew.Load())
+	}
+	if cfg.App.Name != "first" {
+		t.Fatalf("caller's cfg must not be mutated by reload, got %q", cfg.App.Name)
+	}
+
+	cancel()
+	select {
+	case <-w.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watch loop did not stop after cancel")
+	}
+}
+
+func TestWatch_BadReloadKeepsPreviousConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTempYAML(t, dir, "config.yaml", "app:\n  name: good\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reloadErr atomic.Value
+	loader := New("APP", "", "",
+		WithConfigFileSearchPaths(dir),
+		WithStructTagName("mapstructure"),
+		WithErrorHandler(func(err error) { reloadErr.Store(err) }),
+	)
+	var cfg appConfig
+	w, err := loader.Watch(ctx, &cfg, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeTempYAML(t, dir, "config.yaml", "app: [broken\n")
+	waitFor(t, "reload error", func() bool { return reloadErr.Load() != nil })
+	if got := Current[appConfig](w).App.Name; got != "good" {
+		t.Fatalf("previous config lost after bad reload: %q", got)
+	}
+}
+
+// TestWatch_ConfigMapSymlinkSwap mimics a Kubernetes ConfigMap update: config.yaml is a
+// symlink through ..data, and an update only swaps ..data to a new timestamped directory.
+func TestWatch_ConfigMapSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion := func(version, name string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTempYAML(t, filepath.Join(dir, version), "config.yaml", "app:\n  name: "+name+"\n")
+	}
+	writeVersion("..2026_01", "first")
+	if err := os.Symlink("..2026_01", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	loader := New("APP", "", "", WithConfigFileSearchPaths(dir), WithStructTagName("mapstructure"))
+	var cfg appConfig
+	w, err := loader.Watch(ctx, &cfg, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "first" {
+		t.Fatalf("initial load: %q", cfg.App.Name)
+	}
+
+	writeVersion("..2026_02", "second")
+	if err := os.Symlink("..2026_02", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "reload after ..data swap", func() bool { return Current[appConfig](w).App.Name == "second" })
+}
+
+func TestWatch_ConsulPollPicksUpChange(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: v1\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan string, 1)
+	loader := New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+		WithWatchInterval(20*time.Millisecond),
+	)
+	var cfg appConfig
+	_, err := loader.Watch(ctx, &cfg, func(_, new interface{}) {
+		changed <- new.(*appConfig).App.Name
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.App.Name != "v1" {
+		t.Fatalf("expected App.Name=v1 from consul, got %q", cfg.App.Name)
+	}
+
+	fc.set("app:\n  name: v2\n")
+	select {
+	case got := <-changed:
+		if got != "v2" {
+			t.Fatalf("expected v2, got %q", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("consul change was not picked up")
+	}
+}
+
+func TestWatch_TwoStoresShareRemoteLoader(t *testing.T) {
+	fc, addr := newFakeConsul(t, "svc/config", "app:\n  name: one\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loader := New("APP", "svc/config", addr,
+		WithLoadFromConsulMaxAttempt(1),
+		WithStructTagName("mapstructure"),
+		WithWatchInterval(20*time.Millisecond),
+	)
+	a, err := NewStore[appConfig](ctx, loader)
+	if err != nil {
+		t.Fatalf("store a: %v", err)
+	}
+	b, err := NewStore[appConfig](ctx, loader)
+	if err != nil {
+		t.Fatalf("store b: %v", err)
+	}
+
+	fc.set("app:\n  name: two\n")
+	waitFor(t, "both stores to reload", func() bool {
+		return a.Get().App.Name == "two" && b.Get().App.Name == "two"
+	})
+}
@@ -0,0 +1,181 @@
+package errors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+}
+
+// writeFileAtomic replaces path by renaming a hidden temp file over it, so the
+// watcher never sees a truncated file.
+func writeFileAtomic(t *testing.T, path, body string) {
+	t.Helper()
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	writeFile(t, tmp, body)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("rename %s: %v", tmp, err)
+	}
+}
+
+// eventually polls cond for up to 2s; fsnotify + reload is async.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not happen within 2s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFileResolverNestedAndPerDomainFiles(t *testing.T) {
+	dir := t.TempDir()
+	mkdir(t, filepath.Join(dir, "en", "billing"))
+	mkdir(t, filepath.Join(dir, "pt_BR"))
+	mkdir(t, filepath.Join(dir, ".git"))
+	writeFile(t, filepath.Join(dir, "en.yaml"), `common.retry: "Please retry"`)
+	writeFile(t, filepath.Join(dir, "en", "user.yaml"), `
+user:
+  not_found: "User {{.id}} not found"
+  email:
+    taken: "Email {{.email}} taken"
+`)
+	writeFile(t, filepath.Join(dir, "en", "billing", "order.json"), `{"order": {"not_found": "Order {{.id}} not found", "status": {"404": "Missing"}}}`)
+	writeFile(t, filepath.Join(dir, "pt_BR", "user.yml"), `
+user:
+  not_found: "Usuário {{.id}} não encontrado"
+http:
+  404: "Não encontrado"
+`)
+	writeFile(t, filepath.Join(dir, ".git", "en.yaml"), `common.retry: "shadowed"`)
+
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got := r.Locales(); !reflect.DeepEqual(got, []string{"en", "pt-BR"}) {
+		t.Fatalf("Locales() = %v", got)
+	}
+
+	en := ContextWithLocale(context.Background(), "en")
+	pt := ContextWithLocale(context.Background(), "pt-BR")
+	cases := []struct {
+		ctx  context.Context
+		e    *Error
+		want string
+	}{
+		{en, NewValidation("common.retry", ""), "Please retry"},
+		{en, NewNotFound("user.not_found", "").WithArg("id", 1), "User 1 not found"},
+		{en, NewConflict("user.email.taken", "").WithArg("email", "a@b.c"), "Email a@b.c taken"},
+		{en, NewNotFound("order.not_found", "").WithArg("id", 2), "Order 2 not found"},
+		{en, NewNotFound("order.status.404", ""), "Missing"},
+		{pt, NewNotFound("user.not_found", "").WithArg("id", 3), "Usuário 3 não encontrado"},
+		{pt, NewNotFound("http.404", ""), "Não encontrado"},
+	}
+	for _, c := range cases {
+		if got := r.Resolve(c.ctx, c.e); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.e.Code, got, c.want)
+		}
+	}
+}
+
+func TestFileResolverSkipsBlankEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), "user:\n  not_found: \"User not found\"\n  todo:\n")
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatalf("a blank entry must not fail the load: %v", err)
+	}
+	defer r.Close()
+	if got := r.Resolve(context.Background(), NewNotFound("user.todo", "Default text")); got != "Default text" {
+		t.Fatalf("blank entry: got %q, want the Message", got)
+	}
+	if got := r.Resolve(context.Background(), NewNotFound("user.not_found", "")); got != "User not found" {
+		t.Fatalf("sibling entry: got %q", got)
+	}
+}
+
+func TestFileResolverRejectsConflictingFiles(t *testing.T) {
+	dir := t.TempDir()
+	mkdir(t, filepath.Join(dir, "en"))
+	writeFile(t, filepath.Join(dir, "en", "a.yaml"), `user: {not_found: "A"}`)
+	writeFile(t, filepath.Join(dir, "en", "b.yaml"), `user.not_found: "B"`)
+	_, err := NewFileResolver(dir)
+	if err == nil || !strings.Contains(err.Error(), "user.not_found defined in both") {
+		t.Fatalf("expected a duplicate code error, got %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "en", "b.yaml"), "user.not_found: B\nuser:\n  list: [a, b]\n")
+	if _, err := NewFileResolver(dir); err == nil || !strings.Contains(err.Error(), "got a list") {
+		t.Fatalf("expected a list to be rejected, got %v", err)
+	}
+}
+
+func TestFileResolverHotReloadSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	mkdir(t, filepath.Join(dir, "en"))
+	writeFile(t, filepath.Join(dir, "en", "user.yaml"), `user: {not_found: "User not found"}`)
+	writeFile(t, filepath.Join(dir, "en", "order.yaml"), `order: {not_found: "Order not found"}`)
+
+	hookErrs := make(chan error, 16)
+	r, err := NewFileResolver(dir, WithReloadErrorHook(func(e error) { hookErrs <- e }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	en := ContextWithLocale(context.Background(), "en")
+	resolve := func(ctx context.Context, code string) string {
+		return r.Resolve(ctx, NewNotFound(code, "default"))
+	}
+
+	// Editing one file of a locale keeps the entries of its other files.
+	writeFile(t, filepath.Join(dir, "en", "order.yaml"), `order: {not_found: "No such order"}`)
+	eventually(t, "order.yaml reload", func() bool { return resolve(en, "order.not_found") == "No such order" })
+	if got := resolve(en, "user.not_found"); got != "User not found" {
+		t.Fatalf("merge lost user.yaml entries: %q", got)
+	}
+
+	// Adding and removing a file re-merges its locale.
+	writeFile(t, filepath.Join(dir, "en", "cart.yaml"), `cart: {empty: "Cart is empty"}`)
+	eventually(t, "new file pickup", func() bool { return resolve(en, "cart.empty") == "Cart is empty" })
+	if err := os.Remove(filepath.Join(dir, "en", "cart.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "removed file drop", func() bool { return resolve(en, "cart.empty") == "default" })
+
+	// A locale directory created after start is watched too.
+	mkdir(t, filepath.Join(dir, "id", "domain"))
+	writeFile(t, filepath.Join(dir, "id", "domain", "user.yaml"), `user: {not_found: "User tidak ditemukan"}`)
+	id := ContextWithLocale(context.Background(), "id")
+	eventually(t, "new locale pickup", func() bool { return resolve(id, "user.not_found") == "User tidak ditemukan" })
+	writeFile(t, filepath.Join(dir, "id", "domain", "user.yaml"), `user: {not_found: "Pengguna tidak ditemukan"}`)
+	eventually(t, "nested new dir reload", func() bool { return resolve(id, "user.not_found") == "Pengguna tidak ditemukan" })
+
+	// A conflicting edit keeps the previous merge and reports the error.
+	writeFileAtomic(t, filepath.Join(dir, "en", "order.yaml"), `user: {not_found: "dup"}`)
+	select {
+	case err := <-hookErrs:
+		if !strings.Contains(err.Error(), "defined in both") {
+			t.Fatalf("unexpected hook error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("conflicting reload did not report an error")
+	}
+	if got := resolve(en, "order.not_found"); got != "No such order" {
+		t.Fatalf("previous merge lost after bad reload: %q", got)
+	}
+}
@@ -69,6 +69,8 @@ type Error struct {
 	Message string         // default human text; used when no resolver entry matches
 	Args    map[string]any // template variables for the dictionary entry
 	Cause   error          // wrapped underlying error
+
+	stack []uintptr // call site, when the stack policy asked for one
 }
 
 // Error implements the error interface using the DEFAULT message. For a locale-aware,
@@ -103,20 +105,31 @@ func (e *Error) defaultMessage() string {
 }
 
 // New constructs an Error with the given kind, code, and default message.
+// It records the call site when the stack policy covers kind (see SetStackPolicy).
 func New(kind Kind, code, message string) *Error {
-	return &Error{Kind: kind, Code: code, Message: message}
+	return newError(kind, code, message)
 }
 
 // Kind-specific constructors — sugar for readability at call sites.
 
-func NewValidation(code, msg string) *Error   { return New(KindValidation, code, msg) }
-func NewUnauthorized(code, msg string) *Error { return New(KindUnauthorized, code, msg) }
-func NewForbidden(code, msg string) *Error    { return New(KindForbidden, code, msg) }
-func NewNotFound(code, msg string) *Error     { return New(KindNotFound, code, msg) }
-func NewConflict(code, msg string) *Error     { return New(KindConflict, code, msg) }
-func NewTooMany(code, msg string) *Error      { return New(KindTooMany, code, msg) }
-func NewInternal(code, msg string) *Error     { return New(KindInternal, code, msg) }
-func NewUnavailable(code, msg string) *Error  { return New(KindUnavailable, code, msg) }
+func NewValidation(code, msg string) *Error   { return newError(KindValidation, code, msg) }
+func NewUnauthorized(code, msg string) *Error { return newError(KindUnauthorized, code, msg) }
+func NewForbidden(code, msg string) *Error    { return newError(KindForbidden, code, msg) }
+func NewNotFound(code, msg string) *Error     { return newError(KindNotFound, code, msg) }
+func NewConflict(code, msg string) *Error     { return newError(KindConflict, code, msg) }
+func NewTooMany(code, msg string) *Error      { return newError(KindTooMany, code, msg) }
+func NewInternal(code, msg string) *Error     { return newError(KindInternal, code, msg) }
+func NewUnavailable(code, msg string) *Error  { return newError(KindUnavailable, code, msg) }
+
+// newError must be called directly by an exported constructor so the captured
+// stack starts at the constructor's caller.
+func newError(kind Kind, code, message string) *Error {
+	e := &Error{Kind: kind, Code: code, Message: message}
+	if stackEnabled(kind) {
+		e.stack = callers(4) // runtime.Callers, callers, newError, constructor
+	}
+	return e
+}
 
 // WithArgs sets template variables for the dictionary lookup. Chainable.
 func (e *Error) WithArgs(args map[string]any) *Error {
@@ -133,9 +146,13 @@ func (e *Error) WithArg(key string, value any) *Error {
 	return e
 }
 
-// Wrap attaches an underlying cause. Chainable.
+// Wrap attaches an underlying cause. Chainable. An Error built without a stack (e.g.
+// as a struct literal) records the Wrap call site when the stack policy covers its Kind.
 func (e *Error) Wrap(cause error) *Error {
 	e.Cause = cause
+	if e.stack == nil && stackEnabled(e.Kind) {
+		e.stack = callers(3) // runtime.Callers, callers, Wrap
+	}
 	return e
 }
 
@@ -4,40 +4,60 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"text/template"
 
 	"github.com/fsnotify/fsnotify"
+	"golang.org/x/text/language"
 	"gopkg.in/yaml.v3"
 )
 
-// FileResolver watches a directory of dictionary files (one per locale) and resolves
-// Error messages against the corresponding compiled templates.
+// FileResolver watches a directory of dictionary files and resolves Error messages
+// against the corresponding compiled templates. NewFSResolver builds one from an
+// fs.FS instead, such as an embed.FS.
 //
 // File conventions:
-//   - Filename base = locale. "en.yaml" -> "en", "id.json" -> "id".
-//   - Supported extensions: .yaml, .yml, .json.
-//   - File body is a flat map: code -> template string (text/template syntax, e.g. "User {{.id}} not found").
+//   - Locale, a BCP 47 tag: the filename base for files directly in the directory
+//     ("en.yaml" -> "en", "pt_BR.json" -> "pt-BR"), or the first directory below it for
+//     per-domain files ("en/user.yaml", "en/billing/order.yaml" -> "en"). A locale's
+//     files are merged; a code defined in two of them is an error.
+//   - Supported extensions: .yaml, .yml, .json. Hidden files and directories are skipped.
+//   - File body maps code -> template string; nested maps flatten into dotted codes
+//     (user: {not_found: ...} -> user.not_found). Templates use text/template syntax
+//     ("User {{.id}} not found"); with WithICUMessages they may also use ICU style
+//     ("{count, plural, one {# item} other {# items}} left"; see compileMessage).
+//   - Templates can call plural, number, currency and date, formatted for the file's locale.
 //
 // Fallback chain per lookup:
-//   1. requested locale (LocaleFromContext or the resolver's LocaleFunc)
-//   2. default locale (WithDefaultLocale, default "en")
+//   1. each requested locale in rank order (ContextWithLocale / ContextWithLocales, or
+//      the resolver's LocaleFunc), then its BCP 47 parents: pt-BR -> pt. A locale that
+//      is not loaded itself is then matched against the loaded ones: pt -> pt-BR,
+//      sr-Latn -> sr
+//   2. default locale (WithDefaultLocale, default "en"), then its parents
 //   3. Error.Message
 //   4. Error.Code
 //
-// Hot reload: any Write/Create/Rename event on a file re-parses that locale.
-// A parse error on reload keeps the previous dictionary in place and calls the ErrorHook.
+// Hot reload: the watcher covers every subdirectory, including ones created later. A
+// change to a file re-merges all files of its locale; adding or removing a directory
+// reloads everything. A parse error on reload keeps the previous dictionary in place
+// and calls the ErrorHook.
 type FileResolver struct {
-	dir            string
+	dir            string // watched directory; "" when nothing is watched
+	base           map[string]dict // fs.FS layer of NewFSResolver; never reloaded
+	disk           map[string]dict // layer read from dir; owned by watchLoop once started
 	defaultLocale  string
 	localeFunc     LocaleFunc
 	errorHook      func(err error)
-	dicts          atomic.Value // map[string]dict
+	icu            bool // WithICUMessages
+	dicts          atomic.Value // catalog
 	watcher        *fsnotify.Watcher
 	watchClosed    chan struct{}
 	watchOnce      sync.Once
@@ -48,6 +68,47 @@ type dict struct {
 	templates map[string]*template.Template
 }
 
+// catalog is what the resolver swaps in on every load: the dictionaries and a matcher
+// over their locales, replaced together so lookups never mix generations.
+type catalog struct {
+	dicts   map[string]dict
+	matcher language.Matcher // nil when no locale is a valid tag
+	tagged  []string         // locale of each matcher tag, by index
+}
+
+func newCatalog(dicts map[string]dict) catalog {
+	c := catalog{dicts: dicts}
+	var tags []language.Tag
+	for l := range dicts {
+		if _, err := language.Parse(l); err == nil {
+			c.tagged = append(c.tagged, l)
+		}
+	}
+	sort.Strings(c.tagged) // ties in Match go to the earlier tag; keep them stable
+	for _, l := range c.tagged {
+		tags = append(tags, language.MustParse(l))
+	}
+	if len(tags) > 0 {
+		c.matcher = language.NewMatcher(tags)
+	}
+	return c
+}
+
+// match returns the loaded locale closest to locale, if any is close enough to read.
+func (c catalog) match(locale string) (string, bool) {
+	if c.matcher == nil {
+		return "", false
+	}
+	t, err := language.Parse(locale)
+	if err != nil {
+		return "", false
+	}
+	if _, i, conf := c.matcher.Match(t); conf != language.No {
+		return c.tagged[i], true
+	}
+	return "", false
+}
+
 // ResolverOption configures a FileResolver.
 type ResolverOption func(*FileResolver)
 
@@ -57,7 +118,7 @@ func WithDefaultLocale(locale string) ResolverOption {
 }
 
 // WithLocaleFunc overrides how the resolver reads locale from ctx.
-// Default: LocaleFromContext.
+// Default: the locales stored by ContextWithLocale or ContextWithLocales.
 func WithLocaleFunc(fn LocaleFunc) ResolverOption {
 	return func(r *FileResolver) {
 		if fn != nil {
@@ -72,36 +133,104 @@ func WithReloadErrorHook(fn func(err error)) ResolverOption {
 	return func(r *FileResolver) { r.errorHook = fn }
 }
 
-// NewFileResolver loads every supported file in dir and starts a watcher for hot reload.
-// Returns an error if the initial load fails; individual reload failures during runtime
-// are surfaced via the ErrorHook.
-func NewFileResolver(dir string, opts ...ResolverOption) (*FileResolver, error) {
+// WithOverrideDir layers the dictionaries in dir over those of NewFSResolver's fs.FS:
+// an entry in dir replaces the entry for the same locale and code, everything else
+// comes from the fs.FS. dir uses the same layout as NewFileResolver's and is watched
+// for hot reload. A dir that does not exist is skipped, so the override mount can be
+// optional. NewFileResolver ignores this option.
+func WithOverrideDir(dir string) ResolverOption {
+	return func(r *FileResolver) { r.dir = dir }
+}
+
+// WithICUMessages compiles ICU MessageFormat arguments in dictionary entries, so
+// "{count, plural, one {# item} other {# items}}" and "{name}" work next to {{...}}
+// actions (see compileMessage). It is off by default: braces are plain text then,
+// and existing dictionaries with literal "{" or "}" keep loading. Pass it to Lint as
+// well so ICU arguments are checked.
+func WithICUMessages() ResolverOption {
+	return func(r *FileResolver) { r.icu = true }
+}
+
+func newFileResolver(opts []ResolverOption) *FileResolver {
 	r := &FileResolver{
-		dir:           dir,
 		defaultLocale: "en",
-		localeFunc:    LocaleFromContext,
 		watchClosed:   make(chan struct{}),
 	}
 	for _, o := range opts {
 		o(r)
 	}
-	dicts, err := loadAll(dir)
+	r.defaultLocale = canonicalLocale(r.defaultLocale)
+	return r
+}
+
+// NewFileResolver loads every supported file in dir and starts a watcher for hot reload.
+// Returns an error if the initial load fails; individual reload failures during runtime
+// are surfaced via the ErrorHook.
+func NewFileResolver(dir string, opts ...ResolverOption) (*FileResolver, error) {
+	r := newFileResolver(opts)
+	r.dir = dir
+	dicts, err := loadAll(os.DirFS(dir), r.icu)
 	if err != nil {
 		return nil, fmt.Errorf("errors: load dictionaries from %s: %w", dir, err)
 	}
-	r.dicts.Store(dicts)
+	r.disk = dicts
+	r.dicts.Store(newCatalog(dicts))
+	if err := r.startWatcher(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
 
+// NewFSResolver loads the dictionaries in fsys, laid out as for NewFileResolver, e.g.
+// from an embed.FS:
+//
+//	//go:embed messages
+//	var messages embed.FS
+//
+//	sub, _ := fs.Sub(messages, "messages")
+//	resolver, err := errors.NewFSResolver(sub, errors.WithOverrideDir("/etc/app/messages"))
+//
+// fsys is read once and not watched, so it works in read-only containers. Only the
+// directory of WithOverrideDir, if any, is watched; Close is safe either way.
+func NewFSResolver(fsys fs.FS, opts ...ResolverOption) (*FileResolver, error) {
+	r := newFileResolver(opts)
+	base, err := loadAll(fsys, r.icu)
+	if err != nil {
+		return nil, fmt.Errorf("errors: load dictionaries: %w", err)
+	}
+	r.base = base
+	if r.dir != "" {
+		if _, err := os.Stat(r.dir); stderrors.Is(err, fs.ErrNotExist) {
+			r.dir = ""
+		} else {
+			disk, err := loadAll(os.DirFS(r.dir), r.icu)
+			if err != nil {
+				return nil, fmt.Errorf("errors: load dictionaries from %s: %w", r.dir, err)
+			}
+			r.disk = disk
+		}
+	}
+	r.dicts.Store(newCatalog(mergeLayers(r.base, r.disk)))
+	if r.dir != "" {
+		if err := r.startWatcher(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *FileResolver) startWatcher() error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return nil, fmt.Errorf("errors: create watcher: %w", err)
+		return fmt.Errorf("errors: create watcher: %w", err)
 	}
-	if err := watcher.Add(dir); err != nil {
+	if err := watchTree(watcher, r.dir); err != nil {
 		_ = watcher.Close()
-		return nil, fmt.Errorf("errors: watch %s: %w", dir, err)
+		return fmt.Errorf("errors: watch %s: %w", r.dir, err)
 	}
 	r.watcher = watcher
 	go r.watchLoop()
-	return r, nil
+	return nil
 }
 
 // Resolve renders e's message against the dictionary. Fallback chain applies.
@@ -109,16 +238,33 @@ func (r *FileResolver) Resolve(ctx context.Context, e *Error) string {
 	if e == nil {
 		return ""
 	}
-	dicts := r.currentDicts()
-	locale := r.localeFunc(ctx)
+	cat := r.current()
 
-	if msg, ok := r.render(dicts, locale, e); ok {
-		return msg
+	// Same lookup as LocalesFromContext, without allocating for a single locale.
+	var single [1]string
+	var locales []string
+	if r.localeFunc != nil {
+		single[0] = r.localeFunc(ctx)
+		locales = single[:]
+	} else if ctx != nil {
+		switch v := ctx.Value(ctxKey{}).(type) {
+		case []string:
+			locales = v
+		case string:
+			single[0] = v
+			locales = single[:]
+		}
 	}
-	if locale != r.defaultLocale {
-		if msg, ok := r.render(dicts, r.defaultLocale, e); ok {
+	for _, locale := range locales {
+		if msg, ok := r.renderChain(cat.dicts, locale, e); ok {
 			return msg
 		}
+		if msg, ok := r.renderMatch(cat, locale, e); ok {
+			return msg
+		}
+	}
+	if msg, ok := r.renderChain(cat.dicts, r.defaultLocale, e); ok {
+		return msg
 	}
 	if e.Message != "" {
 		return e.Message
@@ -130,12 +276,65 @@ func (r *FileResolver) Resolve(ctx context.Context, e *Error) string {
 func (r *FileResolver) Close() error {
 	var err error
 	r.watchStopOnce.Do(func() {
+		if r.watcher == nil {
+			return
+		}
 		err = r.watcher.Close()
 		<-r.watchClosed
 	})
 	return err
 }
 
+// Locales returns the loaded locales, sorted.
+func (r *FileResolver) Locales() []string {
+	dicts := r.current().dicts
+	out := make([]string, 0, len(dicts))
+	for l := range dicts {
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// renderChain renders e for locale, falling back along its BCP 47 parents. The exact
+// name is tried first so the common case skips tag parsing.
+func (r *FileResolver) renderChain(dicts map[string]dict, locale string, e *Error) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	if msg, ok := r.render(dicts, locale, e); ok {
+		return msg, true
+	}
+	if isBareLanguage(locale) {
+		return "", false // e.g. "en": already canonical and without parents
+	}
+	for _, l := range localeChain(locale) {
+		if l == locale {
+			continue
+		}
+		if msg, ok := r.render(dicts, l, e); ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// renderMatch renders e for the loaded locale closest to locale, e.g. pt-BR for pt.
+// It only runs for a locale that is not loaded itself, so a code missing from a loaded
+// locale goes on to the next requested locale without matching.
+func (r *FileResolver) renderMatch(cat catalog, locale string, e *Error) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	if _, loaded := cat.dicts[locale]; loaded {
+		return "", false
+	}
+	if l, ok := cat.match(locale); ok {
+		return r.render(cat.dicts, l, e)
+	}
+	return "", false
+}
+
 func (r *FileResolver) render(dicts map[string]dict, locale string, e *Error) (string, bool) {
 	if locale == "" {
 		return "", false
@@ -155,11 +354,9 @@ func (r *FileResolver) render(dicts map[string]dict, locale string, e *Error) (s
 	return buf.String(), true
 }
 
-func (r *FileResolver) currentDicts() map[string]dict {
-	if v, ok := r.dicts.Load().(map[string]dict); ok {
-		return v
-	}
-	return nil
+func (r *FileResolver) current() catalog {
+	c, _ := r.dicts.Load().(catalog)
+	return c
 }
 
 func (r *FileResolver) watchLoop() {
@@ -170,83 +367,245 @@ func (r *FileResolver) watchLoop() {
 			if !ok {
 				return
 			}
-			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
-				continue
-			}
-			if !isSupported(ev.Name) {
-				continue
-			}
-			r.reload(ev.Name)
+			r.handleEvent(ev)
 		case err, ok := <-r.watcher.Errors:
 			if !ok {
 				return
 			}
-			if r.errorHook != nil {
-				r.errorHook(err)
-			}
+			r.reportError(err)
+		}
+	}
+}
+
+// handleEvent re-merges the locale a dictionary file belongs to. Directory changes
+// (a locale directory added, removed or renamed) reload every locale, since the files
+// inside produce no events of their own.
+func (r *FileResolver) handleEvent(ev fsnotify.Event) {
+	if isSupported(ev.Name) {
+		if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+			r.reload(ev.Name)
 		}
+		return
+	}
+	switch {
+	case ev.Op&fsnotify.Create != 0:
+		if fi, err := os.Stat(ev.Name); err != nil || !fi.IsDir() {
+			return
+		}
+		if err := watchTree(r.watcher, ev.Name); err != nil {
+			r.reportError(fmt.Errorf("errors: watch %s: %w", ev.Name, err))
+		}
+		r.reloadAll()
+	case ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		r.reloadAll()
+	}
+}
+
+func (r *FileResolver) reportError(err error) {
+	if r.errorHook != nil {
+		r.errorHook(err)
 	}
 }
 
-// reload re-parses a single file and swaps the affected locale into the atomic map.
-// On parse error, the previous dictionary stays put and errorHook is fired.
+// reload re-merges every file of the locale path belongs to and swaps the result into
+// the atomic map. A locale whose last file is gone keeps its previous dictionary, as
+// editors that save by rename briefly remove the file, unless there is a base layer:
+// then the override is dropped and the base entries come back. On parse error, the
+// previous dictionary stays put and errorHook is fired.
 func (r *FileResolver) reload(path string) {
-	locale := localeFromFilename(path)
+	rel, err := filepath.Rel(r.dir, path)
+	if err != nil {
+		return
+	}
+	locale := localeOf(filepath.ToSlash(rel))
 	if locale == "" {
 		return
 	}
-	d, err := loadFile(path)
+	fsys := os.DirFS(r.dir)
+	files, err := localeFiles(fsys)
 	if err != nil {
-		if r.errorHook != nil {
-			r.errorHook(fmt.Errorf("errors: reload %s: %w", path, err))
-		}
+		r.reportError(fmt.Errorf("errors: reload %s: %w", path, err))
+		return
+	}
+	paths := files[locale]
+	if len(paths) == 0 && r.base == nil {
 		return
 	}
 	// atomic swap: build a fresh outer map so readers never see a partial write.
-	prev := r.currentDicts()
-	next := make(map[string]dict, len(prev)+1)
-	for k, v := range prev {
+	next := make(map[string]dict, len(r.disk)+1)
+	for k, v := range r.disk {
 		next[k] = v
 	}
-	next[locale] = d
-	r.dicts.Store(next)
+	if len(paths) == 0 {
+		delete(next, locale)
+	} else {
+		d, err := loadLocale(fsys, locale, paths, r.icu)
+		if err != nil {
+			r.reportError(fmt.Errorf("errors: reload %s: %w", path, err))
+			return
+		}
+		next[locale] = d
+	}
+	r.disk = next
+	r.dicts.Store(newCatalog(mergeLayers(r.base, r.disk)))
 }
 
-// --- file I/O ---
-
-func loadAll(dir string) (map[string]dict, error) {
-	entries, err := os.ReadDir(dir)
+// reloadAll re-reads the whole directory; on error the previous dictionaries stay.
+func (r *FileResolver) reloadAll() {
+	disk, err := loadAll(os.DirFS(r.dir), r.icu)
 	if err != nil {
-		return nil, err
+		r.reportError(fmt.Errorf("errors: reload %s: %w", r.dir, err))
+		return
+	}
+	r.disk = disk
+	r.dicts.Store(newCatalog(mergeLayers(r.base, r.disk)))
+}
+
+// mergeLayers returns base with the entries of over replacing those of the same locale
+// and code. Neither input is modified.
+func mergeLayers(base, over map[string]dict) map[string]dict {
+	if len(base) == 0 {
+		return over
 	}
-	out := make(map[string]dict, len(entries))
-	for _, ent := range entries {
-		if ent.IsDir() {
+	if len(over) == 0 {
+		return base
+	}
+	out := make(map[string]dict, len(base)+len(over))
+	for locale, d := range base {
+		out[locale] = d
+	}
+	for locale, d := range over {
+		b, ok := out[locale]
+		if !ok {
+			out[locale] = d
 			continue
 		}
-		path := filepath.Join(dir, ent.Name())
-		if !isSupported(path) {
-			continue
+		merged := dict{templates: make(map[string]*template.Template, len(b.templates)+len(d.templates))}
+		for code, t := range b.templates {
+			merged.templates[code] = t
 		}
-		locale := localeFromFilename(path)
-		if locale == "" {
-			continue
+		for code, t := range d.templates {
+			merged.templates[code] = t
 		}
-		d, err := loadFile(path)
+		out[locale] = merged
+	}
+	return out
+}
+
+// --- file I/O ---
+
+// watchTree adds root and every directory below it to w; fsnotify does not recurse.
+func watchTree(w *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return nil, fmt.Errorf("load %s: %w", path, err)
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
+
+func loadAll(fsys fs.FS, icu bool) (map[string]dict, error) {
+	files, err := localeFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]dict, len(files))
+	for locale, paths := range files {
+		d, err := loadLocale(fsys, locale, paths, icu)
+		if err != nil {
+			return nil, err
 		}
 		out[locale] = d
 	}
 	return out, nil
 }
 
-func loadFile(path string) (dict, error) {
-	data, err := os.ReadFile(path)
+// localeFiles walks fsys and groups the dictionary files by locale (see localeOf),
+// each group sorted by path. Hidden files and directories are skipped, which also
+// keeps the ..data links of Kubernetes ConfigMap mounts from loading twice.
+func localeFiles(fsys fs.FS) (map[string][]string, error) {
+	out := make(map[string][]string)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		if isHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isSupported(path) {
+			return nil
+		}
+		if locale := localeOf(path); locale != "" {
+			out[locale] = append(out[locale], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, paths := range out {
+		sort.Strings(paths)
+	}
+	return out, nil
+}
+
+// localeOf returns the locale of a dictionary file from its slash-separated path
+// relative to the dictionary root: the file name for files at the root ("en.yaml"),
+// the first directory otherwise ("en/user.yaml", "en/billing/invoice.yaml").
+func localeOf(rel string) string {
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return ""
+	}
+	first, _, nested := strings.Cut(rel, "/")
+	if nested {
+		return canonicalLocale(first)
+	}
+	return localeFromFilename(rel)
+}
+
+// loadLocale merges the files of one locale. A code defined by two files is an error,
+// so the result never depends on file order.
+func loadLocale(fsys fs.FS, locale string, paths []string, icu bool) (dict, error) {
+	merged := dict{templates: make(map[string]*template.Template)}
+	origin := make(map[string]string)
+	for _, path := range paths {
+		d, err := loadFile(fsys, path, locale, icu)
+		if err != nil {
+			return dict{}, fmt.Errorf("load %s: %w", path, err)
+		}
+		for code, t := range d.templates {
+			if prev, ok := origin[code]; ok {
+				return dict{}, fmt.Errorf("code %s defined in both %s and %s", code, prev, path)
+			}
+			origin[code] = path
+			merged.templates[code] = t
+		}
+	}
+	return merged, nil
+}
+
+// loadFile parses one dictionary file. Nested maps flatten into dotted codes, so
+// `user: {not_found: ...}` defines user.not_found. Entries may use the formatting
+// functions of templateFuncs for locale, and ICU message syntax when icu is set (see
+// compileMessage).
+func loadFile(fsys fs.FS, path, locale string, icu bool) (dict, error) {
+	data, err := fs.ReadFile(fsys, path)
 	if err != nil {
 		return dict{}, err
 	}
-	raw := make(map[string]string)
+	var raw map[string]any
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".yaml", ".yml":
@@ -260,9 +619,22 @@ func loadFile(path string) (dict, error) {
 	default:
 		return dict{}, fmt.Errorf("unsupported extension: %s", ext)
 	}
-	templates := make(map[string]*template.Template, len(raw))
-	for code, tmplStr := range raw {
-		t, err := template.New(code).Option("missingkey=zero").Parse(tmplStr)
+	entries := make(map[string]string, len(raw))
+	if err := flatten("", raw, entries); err != nil {
+		return dict{}, err
+	}
+
+	funcs := templateFuncs(locale)
+	templates := make(map[string]*template.Template, len(entries))
+	for code, msg := range entries {
+		if icu {
+			compiled, err := compileMessage(msg)
+			if err != nil {
+				return dict{}, fmt.Errorf("parse message %s: %w", code, err)
+			}
+			msg = compiled
+		}
+		t, err := template.New(code).Funcs(funcs).Option("missingkey=zero").Parse(msg)
 		if err != nil {
 			return dict{}, fmt.Errorf("parse template %s: %w", code, err)
 		}
@@ -271,16 +643,60 @@ func loadFile(path string) (dict, error) {
 	return dict{templates: templates}, nil
 }
 
+// flatten copies the leaves of m into out under dotted keys. Scalars other than
+// strings are formatted with fmt.Sprint; lists are rejected.
+func flatten(prefix string, m map[string]any, out map[string]string) error {
+	for k, v := range m {
+		code := k
+		if prefix != "" {
+			code = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(code, v, out); err != nil {
+				return err
+			}
+			continue
+		case map[any]any: // yaml.v3 when a nested map has non-string keys, e.g. 404:
+			sm := make(map[string]any, len(v))
+			for mk, mv := range v {
+				sm[fmt.Sprint(mk)] = mv
+			}
+			if err := flatten(code, sm, out); err != nil {
+				return err
+			}
+			continue
+		case []any:
+			return fmt.Errorf("code %s: want a message, got a list", code)
+		case nil: // a blank entry (todo:) is a placeholder; Resolve falls back to Message
+			continue
+		}
+		if _, ok := out[code]; ok {
+			return fmt.Errorf("code %s defined twice", code)
+		}
+		if str, ok := v.(string); ok {
+			out[code] = str
+		} else {
+			out[code] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
 func isSupported(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".yaml" || ext == ".yml" || ext == ".json"
 }
 
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
 func localeFromFilename(path string) string {
 	base := filepath.Base(path)
 	ext := filepath.Ext(base)
 	if ext == "" {
 		return ""
 	}
-	return strings.TrimSuffix(base, ext)
+	return canonicalLocale(strings.TrimSuffix(base, ext))
 }
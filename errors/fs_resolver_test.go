@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var embeddedMessages = fstest.MapFS{
+	"en.yaml":         {Data: []byte(`user: {not_found: "User {{.id}} not found", banned: "User banned"}`)},
+	"en/order.yaml":   {Data: []byte(`order.not_found: "Order not found"`)},
+	"id/user.yaml":    {Data: []byte(`user.not_found: "User {{.id}} tidak ditemukan"`)},
+	".hidden/en.yaml": {Data: []byte(`user.banned: "shadowed"`)},
+}
+
+func TestFSResolverLoadsWithoutWatching(t *testing.T) {
+	r, err := NewFSResolver(embeddedMessages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.watcher != nil {
+		t.Fatal("NewFSResolver without an override dir should not start a watcher")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	if got := r.Locales(); !reflect.DeepEqual(got, []string{"en", "id"}) {
+		t.Fatalf("Locales() = %v", got)
+	}
+	id := ContextWithLocale(context.Background(), "id")
+	if got := r.Resolve(id, NewNotFound("user.not_found", "").WithArg("id", 7)); got != "User 7 tidak ditemukan" {
+		t.Fatalf("id: %q", got)
+	}
+	if got := r.Resolve(id, NewNotFound("order.not_found", "")); got != "Order not found" {
+		t.Fatalf("default locale fallback: %q", got)
+	}
+	if got := r.Resolve(context.Background(), NewForbidden("user.banned", "")); got != "User banned" {
+		t.Fatalf("hidden directory was loaded: %q", got)
+	}
+
+	bad := fstest.MapFS{"en.yaml": {Data: []byte(`bad: "{n, plural, one {x}}"`)}}
+	if _, err := NewFSResolver(bad, WithICUMessages()); err == nil || !strings.Contains(err.Error(), "no 'other' case") {
+		t.Fatalf("expected a load error, got %v", err)
+	}
+}
+
+func TestFSResolverOverrideDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `user.banned: "Account suspended"`)
+	writeFile(t, filepath.Join(dir, "pt_BR.yaml"), `user.banned: "Conta suspensa"`)
+
+	r, err := NewFSResolver(embeddedMessages, WithOverrideDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	en := ContextWithLocale(context.Background(), "en")
+	pt := ContextWithLocale(context.Background(), "pt-BR")
+	resolve := func(ctx context.Context, code string) string {
+		return r.Resolve(ctx, NewNotFound(code, "default").WithArg("id", 1))
+	}
+	if got := r.Locales(); !reflect.DeepEqual(got, []string{"en", "id", "pt-BR"}) {
+		t.Fatalf("Locales() = %v", got)
+	}
+	if got := resolve(en, "user.banned"); got != "Account suspended" {
+		t.Fatalf("override not applied: %q", got)
+	}
+	if got := resolve(en, "user.not_found"); got != "User 1 not found" {
+		t.Fatalf("embedded entry lost under override: %q", got)
+	}
+	if got := resolve(pt, "user.banned"); got != "Conta suspensa" {
+		t.Fatalf("override-only locale: %q", got)
+	}
+
+	// The override dir hot-reloads; removing an override falls back to the embedded entry.
+	writeFile(t, filepath.Join(dir, "en.yaml"), `order.not_found: "No such order"`)
+	eventually(t, "override reload", func() bool { return resolve(en, "order.not_found") == "No such order" })
+	if got := resolve(en, "user.banned"); got != "User banned" {
+		t.Fatalf("embedded entry not restored: %q", got)
+	}
+
+	// Removing the last override file of a locale brings the embedded locale back.
+	if err := os.Remove(filepath.Join(dir, "en.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "override removal", func() bool { return resolve(en, "order.not_found") == "Order not found" })
+	if got := resolve(en, "user.banned"); got != "User banned" {
+		t.Fatalf("embedded locale not restored: %q", got)
+	}
+}
+
+func TestFSResolverMissingOverrideDir(t *testing.T) {
+	r, err := NewFSResolver(embeddedMessages, WithOverrideDir(filepath.Join(t.TempDir(), "absent")))
+	if err != nil {
+		t.Fatalf("a missing override dir should be skipped: %v", err)
+	}
+	defer r.Close()
+	if r.watcher != nil {
+		t.Fatal("a missing override dir should not be watched")
+	}
+	if got := r.Resolve(context.Background(), NewForbidden("user.banned", "")); got != "User banned" {
+		t.Fatalf("got %q", got)
+	}
+}
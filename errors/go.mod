@@ -4,6 +4,7 @@ go 1.23.4
 
 require (
 	github.com/fsnotify/fsnotify v1.7.0
+	golang.org/x/text v0.28.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,21 @@
+module github.com/viantonugroho11/go-lib/errors/grpcerrors
+
+go 1.25.0
+
+require (
+	github.com/viantonugroho11/go-lib/errors v0.1.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260526163538-3dc84a4a5aaa
+	google.golang.org/grpc v1.83.0
+)
+
+require (
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	golang.org/x/net v0.55.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	golang.org/x/text v0.37.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Builds against the errors module in this repository until its next release is tagged.
+replace github.com/viantonugroho11/go-lib/errors => ../
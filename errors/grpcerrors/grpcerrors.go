@@ -0,0 +1,172 @@
+// Package grpcerrors maps *errors.Error to gRPC statuses and back. It is a module of
+// its own so the errors package does not pull grpc into programs that never use it.
+package grpcerrors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/viantonugroho11/go-lib/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the ErrorInfo.Domain attached by Status. From reads Code and Args from
+// the ErrorInfo detail carrying this domain, or from the first one if none does.
+const Domain = "go-lib.errors"
+
+// Code maps err's Kind to a gRPC status code. Non-*Error inputs return codes.Unknown.
+func Code(err error) codes.Code {
+	switch errors.KindOf(err) {
+	case errors.KindValidation:
+		return codes.InvalidArgument
+	case errors.KindUnauthorized:
+		return codes.Unauthenticated
+	case errors.KindForbidden:
+		return codes.PermissionDenied
+	case errors.KindNotFound:
+		return codes.NotFound
+	case errors.KindConflict:
+		return codes.AlreadyExists
+	case errors.KindTooMany:
+		return codes.ResourceExhausted
+	case errors.KindInternal:
+		return codes.Internal
+	case errors.KindUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// kindOf is the inverse of Code. Codes with no Kind of their own map to the closest
+// one, e.g. FailedPrecondition and Aborted to KindConflict.
+func kindOf(c codes.Code) errors.Kind {
+	switch c {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return errors.KindValidation
+	case codes.Unauthenticated:
+		return errors.KindUnauthorized
+	case codes.PermissionDenied:
+		return errors.KindForbidden
+	case codes.NotFound:
+		return errors.KindNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return errors.KindConflict
+	case codes.ResourceExhausted:
+		return errors.KindTooMany
+	case codes.Internal, codes.DataLoss, codes.Unimplemented:
+		return errors.KindInternal
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return errors.KindUnavailable
+	default:
+		return errors.KindUnknown
+	}
+}
+
+// Status converts err into a gRPC status. For an *Error the status carries the Kind's
+// code, the default message, and an errdetails.ErrorInfo with Reason = Code and
+// Metadata = Args (values formatted with fmt.Sprint). The Cause is never sent, so
+// KindInternal errors do not leak driver or stack details to callers; clients resolve
+// a localized message from Code + Args with their own dictionary.
+//
+// Errors that already carry a gRPC status pass through unchanged; anything else
+// becomes codes.Unknown. Returns nil for a nil err.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	e := errors.As(err)
+	if e == nil {
+		return status.Convert(err)
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = e.Code
+	}
+	st := status.New(Code(e), msg)
+	info := &errdetails.ErrorInfo{Reason: e.Code, Domain: Domain}
+	if len(e.Args) > 0 {
+		info.Metadata = make(map[string]string, len(e.Args))
+		for k, v := range e.Args {
+			info.Metadata[k] = fmt.Sprint(v)
+		}
+	}
+	if withInfo, derr := st.WithDetails(info); derr == nil {
+		st = withInfo
+	}
+	return st
+}
+
+// From reconstructs an *Error from an error returned by a gRPC call, so callers can
+// switch on Code and use errors.Is across service boundaries. Args come back as
+// strings. An *Error already in err's chain is returned as is. Returns nil for a nil
+// err or an OK status; errors that are not gRPC statuses come back as KindUnknown
+// with err's text as the Message.
+func From(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	if e := errors.As(err); e != nil {
+		return e
+	}
+	st, _ := status.FromError(err)
+	if st.Code() == codes.OK {
+		return nil
+	}
+	e := &errors.Error{Kind: kindOf(st.Code()), Message: st.Message()}
+	if info := errorInfo(st); info != nil {
+		e.Code = info.GetReason()
+		if md := info.GetMetadata(); len(md) > 0 {
+			e.Args = make(map[string]any, len(md))
+			for k, v := range md {
+				e.Args[k] = v
+			}
+		}
+	}
+	return e
+}
+
+// errorInfo returns the ErrorInfo detail set by Status, falling back to the first
+// ErrorInfo from another domain.
+func errorInfo(st *status.Status) *errdetails.ErrorInfo {
+	var first *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+		if info.GetDomain() == Domain {
+			return info
+		}
+		if first == nil {
+			first = info
+		}
+	}
+	return first
+}
+
+// UnaryServerInterceptor converts an *Error returned by a handler, wrapped or not,
+// with Status, so handlers can return it directly. Other errors pass through.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, convert(err)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming handlers.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return convert(handler(srv, ss))
+	}
+}
+
+func convert(err error) error {
+	if errors.As(err) == nil {
+		return err
+	}
+	return Status(err).Err()
+}
@@ -0,0 +1,125 @@
+package grpcerrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/viantonugroho11/go-lib/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// failingHealth returns the error registered for the requested service name.
+type failingHealth struct {
+	healthpb.UnimplementedHealthServer
+	errs map[string]error
+}
+
+func (h failingHealth) Check(_ context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, h.errs[req.GetService()]
+}
+
+func newBufconnClient(t *testing.T, errs map[string]error) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(srv, failingHealth{errs: errs})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestRoundTrip(t *testing.T) {
+	notFound := errors.NewNotFound("user.not_found", "User not found").WithArg("id", 42)
+	internal := errors.NewInternal("user.lookup_failed", "Lookup failed").Wrap(stderrors.New("pg: password authentication failed"))
+	client := newBufconnClient(t, map[string]error{
+		"direct":   notFound,
+		"status":   Status(fmt.Errorf("repo: %w", errors.NewConflict("user.email_taken", "Email taken"))).Err(),
+		"internal": fmt.Errorf("get user: %w", internal),
+		"raw":      stderrors.New("boom"),
+	})
+	ctx := context.Background()
+	call := func(name string) error {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: name})
+		return err
+	}
+
+	err := call("direct")
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("code = %v, want NotFound", status.Code(err))
+	}
+	got := From(err)
+	if got == nil || got.Kind != errors.KindNotFound || got.Code != "user.not_found" || got.Message != "User not found" {
+		t.Fatalf("unexpected reconstruction: %+v", got)
+	}
+	if got.Args["id"] != "42" {
+		t.Fatalf("expected args to round-trip as strings, got %v", got.Args)
+	}
+	if !stderrors.Is(got, notFound) {
+		t.Fatal("errors.Is by Code must match across the boundary")
+	}
+
+	if got := From(call("status")); got.Kind != errors.KindConflict || got.Code != "user.email_taken" {
+		t.Fatalf("unexpected conflict reconstruction: %+v", got)
+	}
+
+	err = call("internal")
+	if status.Code(err) != codes.Internal || status.Convert(err).Message() != "Lookup failed" {
+		t.Fatalf("internal cause must not leak, got %v", err)
+	}
+	if got := From(err); got.Kind != errors.KindInternal || got.Cause != nil {
+		t.Fatalf("unexpected internal reconstruction: %+v", got)
+	}
+
+	if got := From(call("raw")); got.Kind != errors.KindUnknown || got.Code != "" {
+		t.Fatalf("unexpected raw reconstruction: %+v", got)
+	}
+}
+
+func TestCodeMapping(t *testing.T) {
+	cases := map[errors.Kind]codes.Code{
+		errors.KindValidation:   codes.InvalidArgument,
+		errors.KindUnauthorized: codes.Unauthenticated,
+		errors.KindForbidden:    codes.PermissionDenied,
+		errors.KindNotFound:     codes.NotFound,
+		errors.KindConflict:     codes.AlreadyExists,
+		errors.KindTooMany:      codes.ResourceExhausted,
+		errors.KindInternal:     codes.Internal,
+		errors.KindUnavailable:  codes.Unavailable,
+		errors.KindUnknown:      codes.Unknown,
+	}
+	for k, want := range cases {
+		if got := Code(errors.New(k, "x", "x")); got != want {
+			t.Errorf("Kind %v -> %v, want %v", k, got, want)
+		}
+		if back := kindOf(want); back != k {
+			t.Errorf("code %v -> Kind %v, want %v", want, back, k)
+		}
+	}
+	var verrs errors.ValidationErrors
+	verrs.AddNew("email", "email.invalid", "Invalid email")
+	if got := Code(fmt.Errorf("signup: %w", verrs.Err())); got != codes.InvalidArgument {
+		t.Errorf("ValidationErrors -> %v, want InvalidArgument", got)
+	}
+	if Status(nil) != nil || From(nil) != nil || From(status.Error(codes.OK, "")) != nil {
+		t.Fatal("nil and OK must map to nil")
+	}
+}
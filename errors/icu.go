@@ -0,0 +1,219 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compileMessage rewrites the ICU MessageFormat parts of a dictionary entry into
+// text/template syntax, leaving {{...}} actions untouched, so both styles can be mixed:
+//
+//	{name}                                          -> {{.name}}
+//	{total, number}                                 -> {{number .total}}
+//	{at, date}                                      -> {{date .at}}
+//	{count, plural, =0 {No items} one {# item} other {# items}}
+//
+// A plural case may nest other arguments; # inside it is the formatted count. As in
+// ICU, an apostrophe quotes a following brace or #, and a doubled apostrophe is a
+// literal one:
+//
+//	'{'braces'}'  -> {braces}
+//	it''s         -> it's
+func compileMessage(src string) (string, error) {
+	c := &icuCompiler{src: src}
+	out, err := c.message("", false)
+	if err != nil {
+		return "", err
+	}
+	if c.pos < len(c.src) {
+		return "", fmt.Errorf("unexpected '}' at offset %d", c.pos)
+	}
+	return out, nil
+}
+
+type icuCompiler struct {
+	src string
+	pos int
+}
+
+// message compiles text up to the end of input, or up to the closing brace of a plural
+// case when nested. countArg is the argument # stands for, "" outside plural cases.
+func (c *icuCompiler) message(countArg string, nested bool) (string, error) {
+	var b strings.Builder
+	for c.pos < len(c.src) {
+		rest := c.src[c.pos:]
+		switch {
+		case strings.HasPrefix(rest, "{{"):
+			end := strings.Index(rest, "}}")
+			if end < 0 {
+				return "", fmt.Errorf("unclosed action at offset %d", c.pos)
+			}
+			b.WriteString(rest[:end+2])
+			c.pos += end + 2
+		case rest[0] == '{':
+			c.pos++
+			arg, err := c.argument()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(arg)
+		case rest[0] == '}':
+			if nested {
+				return b.String(), nil
+			}
+			return "", fmt.Errorf("unexpected '}' at offset %d", c.pos)
+		case rest[0] == '#' && countArg != "":
+			fmt.Fprintf(&b, "{{number .%s}}", countArg)
+			c.pos++
+		case strings.HasPrefix(rest, "''"):
+			b.WriteByte('\'')
+			c.pos += 2
+		case rest[0] == '\'' && len(rest) > 1 && strings.ContainsRune("{}#", rune(rest[1])):
+			end := strings.IndexByte(rest[1:], '\'')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			writeLiteral(&b, rest[1:1+end])
+			c.pos += end + 2
+		default:
+			b.WriteByte(rest[0])
+			c.pos++
+		}
+	}
+	if nested {
+		return "", fmt.Errorf("unclosed plural case")
+	}
+	return b.String(), nil
+}
+
+// argument compiles one {...} argument; the opening brace is already consumed.
+func (c *icuCompiler) argument() (string, error) {
+	name := c.word()
+	if !validArgName(name) {
+		return "", fmt.Errorf("invalid argument name %q at offset %d", name, c.pos)
+	}
+	if c.consume('}') {
+		return "{{." + name + "}}", nil
+	}
+	if !c.consume(',') {
+		return "", fmt.Errorf("expected ',' or '}' after %q at offset %d", name, c.pos)
+	}
+	switch typ := c.word(); typ {
+	case "number", "date":
+		if !c.consume('}') {
+			return "", fmt.Errorf("expected '}' after %s argument %q", typ, name)
+		}
+		return fmt.Sprintf("{{%s .%s}}", typ, name), nil
+	case "plural":
+		if !c.consume(',') {
+			return "", fmt.Errorf("expected ',' after plural argument %q", name)
+		}
+		return c.plural(name)
+	default:
+		return "", fmt.Errorf("unsupported argument type %q for %q", typ, name)
+	}
+}
+
+// plural compiles the cases of {name, plural, ...} into an if/else chain on pluralKey.
+func (c *icuCompiler) plural(name string) (string, error) {
+	var keys, bodies []string
+	other := -1
+	for {
+		c.skipSpace()
+		if c.consume('}') {
+			break
+		}
+		key := c.word()
+		if key == "" || !c.consume('{') {
+			return "", fmt.Errorf("expected plural case for %q at offset %d", name, c.pos)
+		}
+		body, err := c.message(name, true)
+		if err != nil {
+			return "", err
+		}
+		c.pos++ // closing brace of the case
+		if key == "other" {
+			other = len(keys)
+		}
+		keys = append(keys, key)
+		bodies = append(bodies, body)
+	}
+	if other < 0 {
+		return "", fmt.Errorf("plural argument %q has no 'other' case", name)
+	}
+
+	selector := fmt.Sprintf("(pluralKey .%s", name)
+	for _, k := range keys {
+		selector += fmt.Sprintf(" %q", k)
+	}
+	selector += ")"
+	var b strings.Builder
+	first := true
+	for i, k := range keys {
+		if i == other {
+			continue
+		}
+		if first {
+			b.WriteString("{{if ")
+			first = false
+		} else {
+			b.WriteString("{{else if ")
+		}
+		fmt.Fprintf(&b, "eq %s %q}}%s", selector, k, bodies[i])
+	}
+	if first {
+		return bodies[other], nil
+	}
+	fmt.Fprintf(&b, "{{else}}%s{{end}}", bodies[other])
+	return b.String(), nil
+}
+
+// word reads an identifier-like token (letters, digits, _ . = -), skipping leading space.
+func (c *icuCompiler) word() string {
+	c.skipSpace()
+	start := c.pos
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		if ch == '_' || ch == '.' || ch == '=' || ch == '-' ||
+			('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') {
+			c.pos++
+			continue
+		}
+		break
+	}
+	return c.src[start:c.pos]
+}
+
+// consume skips space and reports whether the next byte is ch, consuming it if so.
+func (c *icuCompiler) consume(ch byte) bool {
+	c.skipSpace()
+	if c.pos < len(c.src) && c.src[c.pos] == ch {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+func (c *icuCompiler) skipSpace() {
+	for c.pos < len(c.src) && strings.IndexByte(" \t\r\n", c.src[c.pos]) >= 0 {
+		c.pos++
+	}
+}
+
+func validArgName(name string) bool {
+	if name == "" || name[0] == '=' || name[0] == '-' || name[0] == '.' || ('0' <= name[0] && name[0] <= '9') {
+		return false
+	}
+	return !strings.ContainsAny(name, "=-")
+}
+
+// writeLiteral writes quoted text, escaping braces so text/template keeps them as is.
+func writeLiteral(b *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '{' {
+			b.WriteString(`{{"{"}}`)
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+)
+
+// ContextWithLocales returns ctx with a ranked list of locales, most preferred first,
+// stored under the same key as ContextWithLocale. FileResolver tries each in turn
+// before its default locale. Use in HTTP middleware with ParseAcceptLanguage:
+//
+//	ctx := errors.ContextWithLocales(r.Context(), errors.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+func ContextWithLocales(ctx context.Context, locales ...string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, locales)
+}
+
+// LocalesFromContext returns the ranked locales stored in ctx by ContextWithLocales,
+// the single locale stored by ContextWithLocale as a one-element list, or nil.
+func LocalesFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	switch v := ctx.Value(ctxKey{}).(type) {
+	case []string:
+		return v
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into BCP 47 tags ranked by
+// quality, e.g. "id-ID,id;q=0.9,en;q=0.5" -> [id-ID id en]. Entries with q=0, the "*"
+// wildcard and duplicates are dropped. A malformed header yields nil.
+func ParseAcceptLanguage(header string) []string {
+	if header == "" {
+		return nil
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if t == language.Und || t == wildcardTag {
+			continue
+		}
+		s := t.String()
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// wildcardTag is what language.ParseAcceptLanguage makes of "*".
+var wildcardTag = language.Make("mul")
+
+// canonicalLocale normalizes a locale name to its BCP 47 form ("pt_BR", "PT-br" ->
+// "pt-BR") so file names and request locales compare equal. Names that are not valid
+// tags are returned unchanged.
+func canonicalLocale(locale string) string {
+	t, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	return t.String()
+}
+
+// localeChain returns locale followed by its BCP 47 parents, e.g. pt-BR -> [pt-BR pt]
+// and en-GB -> [en-GB en-001 en]. Invalid tags yield just the locale itself.
+func localeChain(locale string) []string {
+	t, err := language.Parse(locale)
+	if err != nil {
+		return []string{locale}
+	}
+	var chain []string
+	for ; !t.IsRoot(); t = t.Parent() {
+		chain = append(chain, t.String())
+	}
+	return chain
+}
+
+// isBareLanguage reports whether locale is a lowercase language subtag alone, which is
+// its own canonical form and has no parents to fall back to.
+func isBareLanguage(locale string) bool {
+	for i := 0; i < len(locale); i++ {
+		if c := locale[i]; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
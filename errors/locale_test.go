@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string][]string{
+		"id-ID,id;q=0.9,en;q=0.5":    {"id-ID", "id", "en"},
+		"en;q=0.2, pt-br, fr;q=0, *": {"pt-BR", "en"},
+		"en-US,en-us;q=0.8":          {"en-US"},
+		"":                           nil,
+		"garbage;;;":                 nil,
+	}
+	for header, want := range cases {
+		if got := ParseAcceptLanguage(header); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestFileResolverRegionFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `
+user.not_found: "User {{.id}} not found"
+user.email_taken: "Email taken"
+`)
+	writeFile(t, filepath.Join(dir, "pt.yaml"), `
+user.not_found: "Usuário {{.id}} não encontrado"
+user.email_taken: "E-mail em uso"
+`)
+	writeFile(t, filepath.Join(dir, "pt_BR.yaml"), `
+user.not_found: "Usuário {{.id}} não foi encontrado"
+`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `
+user.not_found: "User {{.id}} tidak ditemukan"
+`)
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got := r.Locales(); !reflect.DeepEqual(got, []string{"en", "id", "pt", "pt-BR"}) {
+		t.Fatalf("Locales() = %v", got)
+	}
+
+	notFound := NewNotFound("user.not_found", "").WithArg("id", 7)
+	taken := NewConflict("user.email_taken", "")
+	cases := []struct {
+		ctx  context.Context
+		e    *Error
+		want string
+	}{
+		{ContextWithLocale(context.Background(), "pt-BR"), notFound, "Usuário 7 não foi encontrado"},
+		{ContextWithLocale(context.Background(), "pt_br"), notFound, "Usuário 7 não foi encontrado"},
+		// pt-BR has no entry for this code: walk to pt before the default.
+		{ContextWithLocale(context.Background(), "pt-BR"), taken, "E-mail em uso"},
+		{ContextWithLocale(context.Background(), "pt-PT"), notFound, "Usuário 7 não encontrado"},
+		// Ranked list: fr is not loaded, id-ID falls back to id.
+		{ContextWithLocales(context.Background(), ParseAcceptLanguage("fr-CA,id-ID;q=0.9,en;q=0.5")...), notFound, "User 7 tidak ditemukan"},
+		// id has no entry for this code: the next ranked locale wins over the default.
+		{ContextWithLocales(context.Background(), "id", "pt"), taken, "E-mail em uso"},
+		{ContextWithLocale(context.Background(), "en-GB"), taken, "Email taken"},
+		{ContextWithLocales(context.Background(), "de", "fr"), notFound, "User 7 not found"},
+	}
+	for i, c := range cases {
+		if got := r.Resolve(c.ctx, c.e); got != c.want {
+			t.Errorf("case %d: got %q, want %q", i, got, c.want)
+		}
+	}
+	if got := LocaleFromContext(ContextWithLocales(context.Background(), "id", "en")); got != "id" {
+		t.Fatalf("LocaleFromContext must return the top-ranked locale, got %q", got)
+	}
+}
+
+func TestFileResolverMatchesLoadedLocales(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `user.not_found: "User not found"`)
+	writeFile(t, filepath.Join(dir, "pt_BR.yaml"), `user.not_found: "Usuário não encontrado"`)
+	writeFile(t, filepath.Join(dir, "sr.yaml"), `user.not_found: "Корисник није пронађен"`)
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	notFound := NewNotFound("user.not_found", "")
+	cases := []struct {
+		locales []string
+		want    string
+	}{
+		// Neither pt nor its parents are loaded: pt-BR is the closest loaded locale.
+		{[]string{"pt"}, "Usuário não encontrado"},
+		{[]string{"pt-PT"}, "Usuário não encontrado"},
+		{[]string{"sr-Latn"}, "Корисник није пронађен"},
+		// A match for a higher-ranked locale wins over a lower-ranked exact hit.
+		{[]string{"pt", "en"}, "Usuário não encontrado"},
+		// Nothing close to fr is loaded: the default locale answers.
+		{[]string{"fr"}, "User not found"},
+	}
+	for _, c := range cases {
+		if got := r.Resolve(ContextWithLocales(context.Background(), c.locales...), notFound); got != c.want {
+			t.Errorf("%v: got %q, want %q", c.locales, got, c.want)
+		}
+	}
+
+	// The matcher follows reloads: a new locale becomes the closest match.
+	writeFile(t, filepath.Join(dir, "pt_PT.yaml"), `user.not_found: "Utilizador não encontrado"`)
+	ptPT := ContextWithLocale(context.Background(), "pt-PT")
+	eventually(t, "pt-PT reload", func() bool { return r.Resolve(ptPT, notFound) == "Utilizador não encontrado" })
+	de := ContextWithLocale(context.Background(), "de")
+	if got := r.Resolve(de, notFound); got != "User not found" {
+		t.Fatalf("de before reload: %q", got)
+	}
+	writeFile(t, filepath.Join(dir, "de_CH.yaml"), `user.not_found: "Benutzer nicht gefunden"`)
+	eventually(t, "de-CH reload", func() bool { return r.Resolve(de, notFound) == "Benutzer nicht gefunden" })
+}
+
+func TestWriteProblemNegotiatesAcceptLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `user.not_found: "User not found"`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `user.not_found: "User tidak ditemukan"`)
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	SetDefaultResolver(r)
+	defer SetDefaultResolver(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.5")
+	if p := NewProblem(req, NewNotFound("user.not_found", "")); p.Title != "User tidak ditemukan" {
+		t.Fatalf("expected the header to pick the locale, got %q", p.Title)
+	}
+	req = req.WithContext(ContextWithLocale(req.Context(), "en"))
+	if p := NewProblem(req, NewNotFound("user.not_found", "")); p.Title != "User not found" {
+		t.Fatalf("a locale in the context must win over the header, got %q", p.Title)
+	}
+}
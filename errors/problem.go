@@ -0,0 +1,253 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProblemContentType is the media type of an RFC 9457 problem details body.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. Code, Kind, Args and Errors are
+// extension members; clients switch on Code, never on Title.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Kind     string         `json:"kind"`
+	Args     map[string]any `json:"args,omitempty"`
+	Errors   []ProblemField `json:"errors,omitempty"`
+}
+
+// ProblemField is one field-level entry of Problem.Errors.
+type ProblemField struct {
+	Field  string         `json:"field"`
+	Code   string         `json:"code,omitempty"`
+	Detail string         `json:"detail"`
+	Args   map[string]any `json:"args,omitempty"`
+}
+
+// ProblemOption configures NewProblem and WriteProblem.
+type ProblemOption func(*problemOptions)
+
+type problemOptions struct {
+	typeBase string
+	instance func(r *http.Request) string
+	fields   map[string]error
+}
+
+// WithProblemTypeBase sets the prefix joined with Code to build Problem.Type
+// (default "urn:problem:", e.g. "urn:problem:user.not_found").
+func WithProblemTypeBase(base string) ProblemOption {
+	return func(o *problemOptions) { o.typeBase = base }
+}
+
+// WithProblemInstance overrides how Problem.Instance is derived from the request,
+// e.g. from a request ID. Default: the request path.
+func WithProblemInstance(fn func(r *http.Request) string) ProblemOption {
+	return func(o *problemOptions) {
+		if fn != nil {
+			o.instance = fn
+		}
+	}
+}
+
+// WithFieldErrors adds field-level errors (field path -> error) to Problem.Errors.
+// Each message is resolved like the top-level title.
+func WithFieldErrors(fields map[string]error) ProblemOption {
+	return func(o *problemOptions) { o.fields = fields }
+}
+
+// NewProblem builds the problem details for err, resolving messages for the locale
+// in r's context, or for r's Accept-Language header when the context carries none.
+// Status comes from StatusCode and Type from Code ("about:blank" when err carries
+// none). The fields of a ValidationErrors in err's chain are listed under Errors, in
+// the order they were recorded, ahead of any WithFieldErrors entries.
+//
+// Causes are never rendered: a non-*Error value gets the HTTP status text as title,
+// not err.Error(). KindInternal and KindUnknown errors also drop Args, so nothing but
+// the configured message reaches the client.
+func NewProblem(r *http.Request, err error, opts ...ProblemOption) *Problem {
+	o := problemOptions{
+		typeBase: "urn:problem:",
+		instance: func(r *http.Request) string { return r.URL.Path },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+		if LocalesFromContext(ctx) == nil {
+			if ranked := ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(ranked) > 0 {
+				ctx = ContextWithLocales(ctx, ranked...)
+			}
+		}
+	}
+
+	status := StatusCode(err)
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  Resolve(ctx, err),
+		Status: status,
+		Code:   CodeOf(err),
+		Kind:   KindOf(err).String(),
+	}
+	if p.Code != "" {
+		p.Type = o.typeBase + p.Code
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(status)
+	}
+	if r != nil && r.URL != nil {
+		p.Instance = o.instance(r)
+	}
+	if e := As(err); e != nil && !hidesDetails(e.Kind) {
+		p.Args = e.Args
+	}
+
+	var verrs ValidationErrors
+	if stderrors.As(err, &verrs) {
+		for _, fe := range verrs {
+			p.Errors = append(p.Errors, problemField(ctx, fe.Field, fe.Err))
+		}
+	}
+	fields := make([]string, 0, len(o.fields))
+	for f := range o.fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		p.Errors = append(p.Errors, problemField(ctx, f, o.fields[f]))
+	}
+	return p
+}
+
+// WriteProblem renders err as an application/problem+json response. See NewProblem.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, opts ...ProblemOption) {
+	p := NewProblem(r, err, opts...)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+func problemField(ctx context.Context, field string, err error) ProblemField {
+	pf := ProblemField{Field: field, Code: CodeOf(err), Detail: Resolve(ctx, err)}
+	if e := As(err); e != nil && !hidesDetails(e.Kind) {
+		pf.Args = e.Args
+	}
+	if pf.Detail == "" {
+		pf.Detail = http.StatusText(StatusCode(err))
+	}
+	return pf
+}
+
+// hidesDetails reports whether errors of kind k are server faults whose Args must
+// not be sent to clients.
+func hidesDetails(k Kind) bool {
+	return k == KindInternal || k == KindUnknown
+}
+
+// maxProblemBody caps how much of a response body FromHTTPResponse reads.
+const maxProblemBody = 64 << 10
+
+// maxBodySnippet caps the raw body quoted in the fallback error's Message.
+const maxBodySnippet = 512
+
+// FromHTTPResponse turns an error response from another service back into an *Error so
+// callers can switch on Code end-to-end. It returns nil for a nil resp or a status
+// below 400. It reads (but does not close) resp.Body.
+//
+// A problem+json body (or a JSON body with a "code" member) gives Code, Args and the
+// title as Message; Kind is inferred from the status (see kindFromStatus). Any other
+// body yields an error without a Code whose Message is a snippet of the raw body and
+// whose Args carry the "status": KindUnavailable for 502, 503 and 504, which usually
+// come from a proxy, KindInternal otherwise.
+func FromHTTPResponse(resp *http.Response) *Error {
+	if resp == nil || resp.StatusCode < 400 {
+		return nil
+	}
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxProblemBody))
+	}
+	if p, ok := decodeProblem(resp.Header.Get("Content-Type"), body); ok {
+		return &Error{Code: p.Code, Kind: kindFromStatus(resp.StatusCode), Message: p.Title, Args: p.Args}
+	}
+
+	kind := KindInternal
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		kind = KindUnavailable
+	}
+	msg := bodySnippet(body)
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &Error{Kind: kind, Message: msg, Args: map[string]any{"status": resp.StatusCode}}
+}
+
+// decodeProblem parses body as problem details when the media type allows it.
+func decodeProblem(contentType string, body []byte) (*Problem, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mt != ProblemContentType && mt != "application/json") {
+		return nil, false
+	}
+	var p Problem
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, false
+	}
+	if mt == "application/json" && p.Code == "" {
+		return nil, false
+	}
+	return &p, true
+}
+
+// kindFromStatus is the inverse of StatusCode. Statuses with no Kind of their own map
+// to the closest one (422 to KindValidation, 412 to KindConflict, 502 and 504 to
+// KindUnavailable); anything else is KindInternal.
+func kindFromStatus(status int) Kind {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return KindValidation
+	case http.StatusUnauthorized:
+		return KindUnauthorized
+	case http.StatusForbidden:
+		return KindForbidden
+	case http.StatusNotFound, http.StatusGone:
+		return KindNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return KindConflict
+	case http.StatusTooManyRequests:
+		return KindTooMany
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return KindUnavailable
+	default:
+		return KindInternal
+	}
+}
+
+// bodySnippet returns the start of body as trimmed, valid UTF-8 text.
+func bodySnippet(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) <= maxBodySnippet {
+		return strings.ToValidUTF8(string(body), "")
+	}
+	cut := maxBodySnippet
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return strings.ToValidUTF8(string(body[:cut]), "") + "..."
+}
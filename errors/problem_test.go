@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readProblem(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+func TestWriteProblemResolvesTitleForLocale(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `user.not_found: "User {{.id}} not found"`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `user.not_found: "User {{.id}} tidak ditemukan"`)
+	res, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+	SetDefaultResolver(res)
+	defer SetDefaultResolver(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?expand=1", nil)
+	req = req.WithContext(ContextWithLocale(req.Context(), "id"))
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, req, NewNotFound("user.not_found", "User not found").WithArg("id", 42))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := readProblem(t, rec)
+	want := map[string]any{
+		"type":     "urn:problem:user.not_found",
+		"title":    "User 42 tidak ditemukan",
+		"status":   float64(404),
+		"instance": "/users/42",
+		"code":     "user.not_found",
+		"kind":     "not_found",
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+	if args, _ := body["args"].(map[string]any); args["id"] != float64(42) {
+		t.Errorf("args = %v", body["args"])
+	}
+}
+
+func TestWriteProblemNeverLeaksInternalCause(t *testing.T) {
+	SetDefaultResolver(nil)
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	secret := stderrors.New("pq: password authentication failed for user admin")
+
+	for _, err := range []error{
+		NewInternal("order.create_failed", "Could not create order").WithArg("dsn", "postgres://admin").Wrap(secret),
+		secret,
+	} {
+		rec := httptest.NewRecorder()
+		WriteProblem(rec, req, err)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d", rec.Code)
+		}
+		raw := rec.Body.String()
+		if strings.Contains(raw, "password") || strings.Contains(raw, "postgres://") {
+			t.Fatalf("internal details leaked: %s", raw)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, req, secret)
+	body := readProblem(t, rec)
+	if body["type"] != "about:blank" || body["title"] != "Internal Server Error" || body["kind"] != "unknown" {
+		t.Fatalf("unexpected problem for raw error: %v", body)
+	}
+}
+
+func TestNewProblemOptionsAndFieldErrors(t *testing.T) {
+	SetDefaultResolver(nil)
+	req := httptest.NewRequest(http.MethodPost, "/signup", nil)
+	req.Header.Set("X-Request-Id", "req-7")
+	p := NewProblem(req, NewValidation("signup.invalid", "Invalid signup"),
+		WithProblemTypeBase("https://errors.example.com/"),
+		WithProblemInstance(func(r *http.Request) string { return "urn:request:" + r.Header.Get("X-Request-Id") }),
+		WithFieldErrors(map[string]error{
+			"password": NewValidation("password.too_short", "Too short").WithArg("min", 8),
+			"email":    NewValidation("email.invalid", "Invalid email"),
+		}),
+	)
+	if p.Type != "https://errors.example.com/signup.invalid" || p.Instance != "urn:request:req-7" || p.Status != 400 {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Field != "email" || p.Errors[1].Code != "password.too_short" ||
+		p.Errors[1].Detail != "Too short" || p.Errors[1].Args["min"] != 8 {
+		t.Fatalf("unexpected field errors: %+v", p.Errors)
+	}
+	if NewProblem(nil, NewConflict("x", "X")).Title != "X" {
+		t.Fatal("a nil request must still resolve")
+	}
+}
+
+func TestFromHTTPResponseRoundTrip(t *testing.T) {
+	SetDefaultResolver(nil)
+	notFound := NewNotFound("user.not_found", "User not found").WithArg("id", 42)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			WriteProblem(w, r, notFound)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"code":"user.email_taken","message":"taken"}`))
+		case "/gateway":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>" + strings.Repeat("bad gateway ", 100) + "</html>"))
+		case "/empty":
+			w.WriteHeader(http.StatusTeapot)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	get := func(path string) *Error {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return FromHTTPResponse(resp)
+	}
+
+	got := get("/problem")
+	if got == nil || got.Kind != KindNotFound || got.Code != "user.not_found" || got.Message != "User not found" || got.Args["id"] != float64(42) {
+		t.Fatalf("unexpected problem reconstruction: %+v", got)
+	}
+	if !stderrors.Is(got, notFound) {
+		t.Fatal("errors.Is by Code must match across the boundary")
+	}
+
+	if got := get("/json"); got.Kind != KindConflict || got.Code != "user.email_taken" {
+		t.Fatalf("unexpected json reconstruction: %+v", got)
+	}
+
+	got = get("/gateway")
+	if got.Kind != KindUnavailable || got.Code != "" || got.Args["status"] != http.StatusBadGateway {
+		t.Fatalf("unexpected gateway fallback: %+v", got)
+	}
+	if !strings.HasPrefix(got.Message, "<html>bad gateway") || len(got.Message) > maxBodySnippet+3 {
+		t.Fatalf("expected a bounded body snippet, got %q", got.Message)
+	}
+
+	if got := get("/empty"); got.Kind != KindInternal || got.Message != "I'm a teapot" {
+		t.Fatalf("unexpected empty-body fallback: %+v", got)
+	}
+	if got := get("/ok"); got != nil {
+		t.Fatalf("expected nil for a success response, got %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/template/parse"
+)
+
+// Definition declares a Code once, with its Kind, default message and the template
+// variables its dictionary entries may use. Build errors from it with New:
+//
+//	var ErrUserNotFound = errors.Define(errors.KindNotFound, "user.not_found", "User not found", "id")
+//
+//	return ErrUserNotFound.New().WithArg("id", id)
+//
+// A Definition also matches its errors with errors.Is: errors.Is(err, ErrUserNotFound).
+type Definition struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Args    []string // declared template variables
+}
+
+// New returns a fresh *Error for d. It records the call site when the stack policy
+// covers d.Kind.
+func (d *Definition) New() *Error {
+	return newError(d.Kind, d.Code, d.Message)
+}
+
+// Error makes a Definition usable as an errors.Is target.
+func (d *Definition) Error() string {
+	return fmt.Sprintf("[%s] %s", d.Code, d.Message)
+}
+
+// As lets errors.As and *Error.Is see d as an *Error with its Code.
+func (d *Definition) As(target any) bool {
+	t, ok := target.(**Error)
+	if !ok {
+		return false
+	}
+	*t = &Error{Kind: d.Kind, Code: d.Code, Message: d.Message}
+	return true
+}
+
+// Registry is a catalog of Definitions, keyed by Code. Most programs use the package
+// registry through Define and Lint.
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]*Definition
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{defs: make(map[string]*Definition)}
+}
+
+// Define registers code and returns its Definition. It panics when code is empty or
+// already defined, since definitions are package-level vars and a clash is a
+// programming error to catch at init.
+func (r *Registry) Define(kind Kind, code, message string, args ...string) *Definition {
+	if code == "" {
+		panic("errors: Define with empty code")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.defs[code]; ok {
+		panic(fmt.Sprintf("errors: code %q defined twice", code))
+	}
+	d := &Definition{Kind: kind, Code: code, Message: message, Args: args}
+	r.defs[code] = d
+	return d
+}
+
+// Lookup returns the Definition for code.
+func (r *Registry) Lookup(code string) (*Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.defs[code]
+	return d, ok
+}
+
+// Definitions returns every Definition sorted by Code.
+func (r *Registry) Definitions() []*Definition {
+	r.mu.RLock()
+	out := make([]*Definition, 0, len(r.defs))
+	for _, d := range r.defs {
+		out = append(out, d)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the package registry used by Define and Lint.
+func DefaultRegistry() *Registry { return defaultRegistry }
+
+// Define registers code in the package registry. See Registry.Define.
+func Define(kind Kind, code, message string, args ...string) *Definition {
+	return defaultRegistry.Define(kind, code, message, args...)
+}
+
+// Lint checks the dictionaries in dir against the package registry. See Registry.Lint.
+func Lint(dir string, opts ...ResolverOption) ([]LintIssue, error) {
+	return defaultRegistry.Lint(dir, opts...)
+}
+
+// LintCheck names the rule a LintIssue broke.
+type LintCheck string
+
+const (
+	// LintMissing: a defined code has no entry in the locale; it falls back to the
+	// default locale or Message.
+	LintMissing LintCheck = "missing"
+	// LintUnknown: the locale has an entry for a code nobody defined, often a typo.
+	LintUnknown LintCheck = "unknown"
+	// LintUndeclaredArg: an entry uses a template variable its Definition does not
+	// declare; it renders empty.
+	LintUndeclaredArg LintCheck = "undeclared_arg"
+)
+
+// LintIssue is one finding of Lint.
+type LintIssue struct {
+	Locale string
+	Code   string
+	Check  LintCheck
+	Arg    string // the variable, for LintUndeclaredArg
+}
+
+func (i LintIssue) String() string {
+	if i.Arg != "" {
+		return fmt.Sprintf("%s: %s: %s %q", i.Locale, i.Code, i.Check, i.Arg)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Locale, i.Code, i.Check)
+}
+
+// Lint loads the dictionaries in dir the way a FileResolver built with opts does and
+// reports, per locale, defined codes without an entry, entries for undefined codes,
+// and template variables not declared as args. Issues are sorted by locale and code.
+// The error is non-nil only when the files cannot be loaded. Run it from a test to
+// keep dictionaries and code in step:
+//
+//	issues, err := errors.Lint("./messages", errors.WithICUMessages())
+func (r *Registry) Lint(dir string, opts ...ResolverOption) ([]LintIssue, error) {
+	dicts, err := loadAll(os.DirFS(dir), newFileResolver(opts).icu)
+	if err != nil {
+		return nil, fmt.Errorf("errors: lint %s: %w", dir, err)
+	}
+	defs := r.Definitions()
+	var issues []LintIssue
+	for locale, d := range dicts {
+		for _, def := range defs {
+			if _, ok := d.templates[def.Code]; !ok {
+				issues = append(issues, LintIssue{Locale: locale, Code: def.Code, Check: LintMissing})
+			}
+		}
+		for code, tmpl := range d.templates {
+			def, ok := r.Lookup(code)
+			if !ok {
+				issues = append(issues, LintIssue{Locale: locale, Code: code, Check: LintUnknown})
+				continue
+			}
+			declared := make(map[string]bool, len(def.Args))
+			for _, a := range def.Args {
+				declared[a] = true
+			}
+			for _, v := range templateVars(tmpl.Tree) {
+				if !declared[v] {
+					issues = append(issues, LintIssue{Locale: locale, Code: code, Check: LintUndeclaredArg, Arg: v})
+				}
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Locale != b.Locale {
+			return a.Locale < b.Locale
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		return a.Arg < b.Arg
+	})
+	return issues, nil
+}
+
+// templateVars returns the top-level fields of dot ({{.id}} -> "id") a template reads,
+// sorted. Fields inside range and with bodies are relative to a new dot and skipped.
+func templateVars(tree *parse.Tree) []string {
+	if tree == nil || tree.Root == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var walkPipe func(p *parse.PipeNode)
+	var walk func(n parse.Node)
+	walkPipe = func(p *parse.PipeNode) {
+		if p == nil {
+			return
+		}
+		for _, cmd := range p.Cmds {
+			for _, arg := range cmd.Args {
+				walk(arg)
+			}
+		}
+	}
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walkPipe(n.Pipe)
+		case *parse.PipeNode:
+			walkPipe(n)
+		case *parse.FieldNode:
+			seen[n.Ident[0]] = true
+		case *parse.IfNode:
+			walkPipe(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walkPipe(n.Pipe)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe)
+		}
+	}
+	walk(tree.Root)
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
@@ -0,0 +1,79 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var errTestOrderMissing = Define(KindNotFound, "test.order_missing", "Order not found", "id")
+
+func TestDefineBuildsAndMatchesErrors(t *testing.T) {
+	e := errTestOrderMissing.New().WithArg("id", 7)
+	if e.Kind != KindNotFound || e.Code != "test.order_missing" || e.Message != "Order not found" {
+		t.Fatalf("unexpected error: %+v", e)
+	}
+	if errTestOrderMissing.New() == errTestOrderMissing.New() {
+		t.Fatal("New must return a fresh error each call")
+	}
+	wrapped := fmt.Errorf("checkout: %w", e)
+	if !stderrors.Is(wrapped, errTestOrderMissing) {
+		t.Fatal("errors.Is must match a Definition by Code")
+	}
+	if stderrors.Is(NewNotFound("test.other", ""), errTestOrderMissing) {
+		t.Fatal("errors.Is must not match another Code")
+	}
+	if d, ok := DefaultRegistry().Lookup("test.order_missing"); !ok || d != errTestOrderMissing {
+		t.Fatal("Define must register in the package registry")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("defining a code twice must panic")
+		}
+	}()
+	Define(KindConflict, "test.order_missing", "again")
+}
+
+func TestRegistryLint(t *testing.T) {
+	reg := NewRegistry()
+	reg.Define(KindNotFound, "user.not_found", "User not found", "id")
+	reg.Define(KindConflict, "user.email_taken", "Email taken", "email")
+	reg.Define(KindTooMany, "auth.locked", "Locked", "minutes")
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `
+user.not_found: "User {{.id}} not found"
+user.email_taken: "Email {{.email}} taken{{if .since}} since {{.since}}{{end}}"
+auth.locked: "{{range .reasons}}{{.text}} {{end}}Try again in {{.minutes}} minutes"
+`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `
+user.notfound: "User {{.id}} tidak ditemukan"
+user.email_taken: "Email {{.mail}} sudah terdaftar"
+`)
+
+	issues, err := reg.Lint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LintIssue{
+		{Locale: "en", Code: "auth.locked", Check: LintUndeclaredArg, Arg: "reasons"},
+		{Locale: "en", Code: "user.email_taken", Check: LintUndeclaredArg, Arg: "since"},
+		{Locale: "id", Code: "auth.locked", Check: LintMissing},
+		{Locale: "id", Code: "user.email_taken", Check: LintUndeclaredArg, Arg: "mail"},
+		{Locale: "id", Code: "user.not_found", Check: LintMissing},
+		{Locale: "id", Code: "user.notfound", Check: LintUnknown},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Fatalf("unexpected issues:\n got %v\nwant %v", issues, want)
+	}
+	if s := want[3].String(); s != `id: user.email_taken: undeclared_arg "mail"` {
+		t.Fatalf("String() = %s", s)
+	}
+
+	if _, err := reg.Lint(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("an unreadable directory must fail")
+	}
+}
@@ -24,7 +24,8 @@ func ContextWithLocale(ctx context.Context, locale string) context.Context {
 	return context.WithValue(ctx, ctxKey{}, locale)
 }
 
-// LocaleFromContext returns the locale stored in ctx, or "" if none.
+// LocaleFromContext returns the locale stored in ctx, or "" if none. With a ranked
+// list stored by ContextWithLocales it returns the first entry.
 func LocaleFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
@@ -32,6 +33,9 @@ func LocaleFromContext(ctx context.Context) string {
 	if v, ok := ctx.Value(ctxKey{}).(string); ok {
 		return v
 	}
+	if v, ok := ctx.Value(ctxKey{}).([]string); ok && len(v) > 0 {
+		return v[0]
+	}
 	return ""
 }
 
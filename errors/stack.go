@@ -0,0 +1,122 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// maxStackDepth bounds how many frames an Error records.
+const maxStackDepth = 32
+
+// StackPolicy decides whether New, the NewX constructors and Wrap record the call
+// site of an Error of kind k. Capturing costs about a microsecond, so keep it to the
+// kinds you investigate from logs.
+type StackPolicy func(k Kind) bool
+
+// StackAll captures a stack for every Kind.
+func StackAll(Kind) bool { return true }
+
+// StackKinds captures a stack only for the given kinds, e.g.
+// StackKinds(KindInternal, KindUnavailable).
+func StackKinds(kinds ...Kind) StackPolicy {
+	set := make(map[Kind]bool, len(kinds))
+	for _, k := range kinds {
+		set[k] = true
+	}
+	return func(k Kind) bool { return set[k] }
+}
+
+// policyBox keeps the concrete type stored in atomic.Value consistent, as resolverBox does.
+type policyBox struct{ p StackPolicy }
+
+var stackPolicy atomic.Value // holds policyBox
+
+// SetStackPolicy installs the package-wide capture policy. Nil (the default) turns
+// capture off. Set it at boot:
+//
+//	errors.SetStackPolicy(errors.StackKinds(errors.KindInternal))
+func SetStackPolicy(p StackPolicy) {
+	stackPolicy.Store(policyBox{p: p})
+}
+
+func stackEnabled(k Kind) bool {
+	b, _ := stackPolicy.Load().(policyBox)
+	return b.p != nil && b.p(k)
+}
+
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	return append([]uintptr(nil), pcs[:n]...)
+}
+
+// Frame is one call site of a captured stack, shaped for log encoders.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// StackTrace returns the frames recorded when e was built, innermost first, or nil
+// when none were captured.
+func (e *Error) StackTrace() []Frame {
+	if e == nil || len(e.stack) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(e.stack)
+	out := make([]Frame, 0, len(e.stack))
+	for {
+		f, more := frames.Next()
+		out = append(out, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// Frames returns the stack of the innermost *Error in err's chain that recorded one,
+// i.e. the one closest to where the failure started. Nil when none did.
+func Frames(err error) []Frame {
+	var frames []Frame
+	for ; err != nil; err = stderrors.Unwrap(err) {
+		if e, ok := err.(*Error); ok && len(e.stack) > 0 {
+			frames = e.StackTrace()
+		}
+	}
+	return frames
+}
+
+// Format implements fmt.Formatter. %s and %v print Error(), %q a quoted Error(), and
+// %+v the code and message, the recorded frames, then the cause chain, each cause
+// itself formatted with %+v:
+//
+//	[user.lookup_failed] Lookup failed
+//	    main.(*Repo).Find
+//	        /src/repo.go:42
+//	    ...
+//	caused by: pq: connection refused
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		if e == nil {
+			_, _ = io.WriteString(s, "<nil>")
+			return
+		}
+		_, _ = fmt.Fprintf(s, "[%s] %s", e.Code, e.defaultMessage())
+		for _, f := range e.StackTrace() {
+			_, _ = fmt.Fprintf(s, "\n    %s\n        %s:%d", f.Function, f.File, f.Line)
+		}
+		if e.Cause != nil {
+			_, _ = fmt.Fprintf(s, "\ncaused by: %+v", e.Cause)
+		}
+	case verb == 'q':
+		_, _ = io.WriteString(s, strconv.Quote(e.Error()))
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
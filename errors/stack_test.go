@@ -0,0 +1,85 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStackCaptureFollowsPolicy(t *testing.T) {
+	defer SetStackPolicy(nil)
+
+	if e := NewInternal("x", "x"); e.StackTrace() != nil {
+		t.Fatal("capture must be off by default")
+	}
+
+	SetStackPolicy(StackKinds(KindInternal))
+	if e := NewNotFound("x", "x"); e.StackTrace() != nil {
+		t.Fatal("kinds outside the policy must not capture")
+	}
+	for name, e := range map[string]*Error{
+		"New":         New(KindInternal, "x", "x"),
+		"NewInternal": NewInternal("x", "x"),
+		"Wrap":        (&Error{Kind: KindInternal, Code: "x"}).Wrap(stderrors.New("cause")),
+	} {
+		frames := e.StackTrace()
+		if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, ".TestStackCaptureFollowsPolicy") {
+			t.Fatalf("%s: stack must start at the caller, got %+v", name, frames)
+		}
+		if !strings.HasSuffix(frames[0].File, "stack_test.go") || frames[0].Line == 0 {
+			t.Fatalf("%s: unexpected first frame %+v", name, frames[0])
+		}
+	}
+
+	SetStackPolicy(StackAll)
+	if NewValidation("x", "x").StackTrace() == nil {
+		t.Fatal("StackAll must capture every kind")
+	}
+	var verrs ValidationErrors
+	verrs.AddNew("email", "email.invalid", "Invalid email")
+	if e := As(verrs.Err()); e == nil || e.StackTrace() != nil {
+		t.Fatal("the validation aggregate is a value, like a Definition, and must not capture")
+	}
+}
+
+func lookupUser() error {
+	return NewInternal("user.lookup_failed", "Lookup failed").Wrap(stderrors.New("pq: connection refused"))
+}
+
+func TestFormatAndFrames(t *testing.T) {
+	SetStackPolicy(StackAll)
+	defer SetStackPolicy(nil)
+
+	inner := lookupUser()
+	outer := NewUnavailable("profile.unavailable", "Profile unavailable").Wrap(inner)
+
+	if got := fmt.Sprintf("%v", outer); got != outer.Error() {
+		t.Fatalf("%%v = %q, want Error()", got)
+	}
+	if got := fmt.Sprintf("%q", inner); got != `"[user.lookup_failed] Lookup failed: pq: connection refused"` {
+		t.Fatalf("%%q = %s", got)
+	}
+
+	out := fmt.Sprintf("%+v", outer)
+	for _, want := range []string{
+		"[profile.unavailable] Profile unavailable\n    ",
+		".TestFormatAndFrames\n        ",
+		"stack_test.go:",
+		"\ncaused by: [user.lookup_failed] Lookup failed\n    ",
+		".lookupUser\n        ",
+		"\ncaused by: pq: connection refused",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%%+v output missing %q:\n%s", want, out)
+		}
+	}
+
+	frames := Frames(fmt.Errorf("handler: %w", outer))
+	if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, ".lookupUser") {
+		t.Fatalf("Frames must return the innermost stack, got %+v", frames)
+	}
+	if Frames(stderrors.New("raw")) != nil {
+		t.Fatal("errors without a stack have no frames")
+	}
+}
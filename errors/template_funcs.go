@@ -0,0 +1,196 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// templateFuncs returns the functions available to the dictionary templates of locale:
+//
+//	{{plural .count "one" "# item left" "other" "# items left"}}  CLDR plural form; "=0" keys match exactly, # is the formatted count
+//	{{number .total}}                                             1234567.5 -> "1,234,567.5" (en), "1.234.567,5" (id)
+//	{{currency .amount "IDR"}}                                    "Rp 15.000" (id); the ISO code may come from an arg
+//	{{date .at}} {{date .at "2 Jan 2006"}}                        short numeric date, or a time.Format layout
+//
+// Numbers may be any Go number or a numeric string (gRPC and HTTP clients receive args
+// as strings or float64). Dates may be a time.Time or an RFC 3339 string.
+//
+// Plural forms and number separators come from x/text's CLDR data. currency and date
+// are approximations: currency is x/text's symbol plus a space plus the amount ("$
+// 1,234.50" where CLDR en-US writes "$1,234.50"), and date uses dateLayout.
+func templateFuncs(locale string) template.FuncMap {
+	tag, _ := language.Parse(locale)
+	p := message.NewPrinter(tag)
+	return template.FuncMap{
+		"plural": func(count any, cases ...string) (string, error) {
+			if len(cases)%2 != 0 {
+				return "", fmt.Errorf("plural: want key/text pairs, got %d values", len(cases))
+			}
+			keys := make([]string, 0, len(cases)/2)
+			for i := 0; i < len(cases); i += 2 {
+				keys = append(keys, cases[i])
+			}
+			key := pluralKey(tag, count, keys)
+			for i := 0; i < len(cases); i += 2 {
+				if cases[i] == key {
+					return strings.ReplaceAll(cases[i+1], "#", formatNumber(p, count)), nil
+				}
+			}
+			return "", nil
+		},
+		"pluralKey": func(count any, keys ...string) string {
+			return pluralKey(tag, count, keys)
+		},
+		"number": func(v any) string {
+			return formatNumber(p, v)
+		},
+		"currency": func(v any, code string) (string, error) {
+			unit, err := currency.ParseISO(code)
+			if err != nil {
+				return "", fmt.Errorf("currency: %w", err)
+			}
+			n, ok := toFloat(v)
+			if !ok {
+				return fmt.Sprint(v), nil
+			}
+			return p.Sprint(currency.Symbol(unit.Amount(n))), nil
+		},
+		"date": func(v any, layout ...string) string {
+			t, ok := toTime(v)
+			if !ok {
+				return fmt.Sprint(v)
+			}
+			if len(layout) > 0 {
+				return t.Format(layout[0])
+			}
+			return t.Format(dateLayout(tag))
+		},
+	}
+}
+
+// pluralKey picks the case for count among keys: an exact "=N" key first, then the
+// CLDR plural form name of count in tag ("zero", "one", "two", "few", "many"), then
+// "other".
+func pluralKey(tag language.Tag, count any, keys []string) string {
+	n, ok := toFloat(count)
+	if !ok {
+		return "other"
+	}
+	has := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		has[k] = true
+	}
+	if exact := "=" + strconv.FormatFloat(n, 'f', -1, 64); has[exact] {
+		return exact
+	}
+	if form := pluralForm(tag, n); has[form] {
+		return form
+	}
+	return "other"
+}
+
+// pluralForm returns the CLDR cardinal plural category of n in tag.
+func pluralForm(tag language.Tag, n float64) string {
+	s := strconv.FormatFloat(math.Abs(n), 'f', -1, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	i, err := strconv.Atoi(intPart)
+	if err != nil {
+		return "other" // too large for the CLDR operands
+	}
+	f, _ := strconv.Atoi(frac)
+	switch plural.Cardinal.MatchPlural(tag, i, len(frac), len(frac), f, f) {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	default:
+		return "other"
+	}
+}
+
+func formatNumber(p *message.Printer, v any) string {
+	n, ok := toFloat(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	return p.Sprint(number.Decimal(n))
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dateLayout approximates the numeric short date of tag with a small table, not CLDR
+// data: month first for US English, day first with dots for languages that write it
+// so, ISO 8601 for East Asian languages, and day/month/year otherwise.
+func dateLayout(tag language.Tag) string {
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	switch base.String() {
+	case "en":
+		if r := region.String(); r == "US" || r == "ZZ" {
+			return "01/02/2006"
+		}
+		return "02/01/2006"
+	case "de", "ru", "pl", "tr", "cs", "fi", "nb", "da", "uk", "ro":
+		return "02.01.2006"
+	case "ja", "zh", "ko", "hu", "sv", "lt":
+		return "2006-01-02"
+	case "und":
+		return "2006-01-02"
+	default:
+		return "02/01/2006"
+	}
+}
@@ -0,0 +1,150 @@
+package errors
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICUPluralAndFormattingPerLocale(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `
+cart.remaining: "{count, plural, =0 {No items} one {# item} other {# items}} left in {name}'s cart"
+cart.total: "Total {{currency .amount .currency}} for {{number .units}} units, due {{date .due}}"
+cart.inline: "{{plural .count \"one\" \"# seat\" \"other\" \"# seats\"}} booked"
+`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `
+cart.remaining: "{count, plural, other {# barang}} tersisa di keranjang {name}"
+cart.total: "Total {{currency .amount .currency}} untuk {total, number} unit, jatuh tempo {due, date}"
+`)
+	writeFile(t, filepath.Join(dir, "ru.yaml"), `
+cart.remaining: "{count, plural, one {# товар} few {# товара} many {# товаров} other {# товара}}"
+`)
+	r, err := NewFileResolver(dir, WithICUMessages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	resolve := func(locale string, e *Error) string {
+		return r.Resolve(ContextWithLocale(context.Background(), locale), e)
+	}
+	remaining := func(count any) *Error {
+		return NewValidation("cart.remaining", "").WithArgs(map[string]any{"count": count, "name": "Ana"})
+	}
+	cases := []struct {
+		locale string
+		e      *Error
+		want   string
+	}{
+		{"en", remaining(0), "No items left in Ana's cart"},
+		{"en", remaining(1), "1 item left in Ana's cart"},
+		{"en", remaining(1234), "1,234 items left in Ana's cart"},
+		{"en", remaining("1"), "1 item left in Ana's cart"}, // args as received over gRPC
+		{"en", remaining(1.5), "1.5 items left in Ana's cart"},
+		{"id", remaining(1234), "1.234 barang tersisa di keranjang Ana"},
+		{"ru", remaining(1), "1 товар"},
+		{"ru", remaining(3), "3 товара"},
+		{"ru", remaining(11), "11 товаров"},
+		{"ru", remaining(21), "21 товар"},
+		{"en", NewValidation("cart.inline", "").WithArg("count", 1), "1 seat booked"},
+		{"en", NewValidation("cart.inline", "").WithArg("count", 2), "2 seats booked"},
+	}
+	for _, c := range cases {
+		if got := resolve(c.locale, c.e); got != c.want {
+			t.Errorf("%s %v: got %q, want %q", c.locale, c.e.Args, got, c.want)
+		}
+	}
+
+	due := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	total := NewValidation("cart.total", "").WithArgs(map[string]any{
+		"amount": 1234.5, "currency": "USD", "units": 1500, "total": 1500, "due": due,
+	})
+	// x/text's currency output, not CLDR's "$1,234.50"; see templateFuncs.
+	if got := resolve("en", total); got != "Total $ 1,234.50 for 1,500 units, due 03/04/2026" {
+		t.Errorf("en total: %q", got)
+	}
+	total.Args["currency"] = "IDR"
+	total.Args["amount"] = 150000
+	total.Args["due"] = due.Format(time.RFC3339)
+	if got := resolve("id", total); got != "Total Rp 150.000 untuk 1.500 unit, jatuh tempo 04/03/2026" {
+		t.Errorf("id total: %q", got)
+	}
+}
+
+func TestCompileMessage(t *testing.T) {
+	cases := map[string]string{
+		"User {{.id}} not found":                "User {{.id}} not found",
+		"Hello {name}":                          "Hello {{.name}}",
+		"{n, number} of {at, date}":             "{{number .n}} of {{date .at}}",
+		"Use '{'braces'}' and # and it''s fine": `Use {{"{"}}braces} and # and it's fine`,
+		"{n, plural, other {# x}}":              "{{number .n}} x",
+		"{n, plural, one {a {who}} other {b}}":  `{{if eq (pluralKey .n "one" "other") "one"}}a {{.who}}{{else}}b{{end}}`,
+	}
+	for in, want := range cases {
+		got, err := compileMessage(in)
+		if err != nil || got != want {
+			t.Errorf("compileMessage(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{
+		"{n, plural, one {x}}",
+		"{n, plural, one {x} other {y}",
+		"{n, select, a {x} other {y}}",
+		"{1bad}",
+		"stray }",
+		"{{.unclosed",
+	} {
+		if _, err := compileMessage(bad); err == nil {
+			t.Errorf("compileMessage(%q) should fail", bad)
+		}
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `bad: "{n, plural, one {x}}"`)
+	if _, err := NewFileResolver(dir, WithICUMessages()); err == nil || !strings.Contains(err.Error(), "no 'other' case") {
+		t.Fatalf("expected a load error naming the problem, got %v", err)
+	}
+}
+
+func TestLintSeesICUArguments(t *testing.T) {
+	reg := NewRegistry()
+	reg.Define(KindValidation, "cart.remaining", "Items left", "count")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `cart.remaining: "{count, plural, one {# item in {cart}} other {# items}}"`)
+	issues, err := reg.Lint(dir, WithICUMessages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Check != LintUndeclaredArg || issues[0].Arg != "cart" {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+}
+
+func TestICUIsOptIn(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `
+set.literal: "b {c} d"
+json.expected: "Expected {"
+smile: "Smile :}"
+count: "{{number .n}} items"
+`)
+	r, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatalf("braces must stay plain text without WithICUMessages: %v", err)
+	}
+	defer r.Close()
+	cases := map[*Error]string{
+		NewValidation("set.literal", "").WithArg("c", "x"): "b {c} d",
+		NewValidation("json.expected", ""):                 "Expected {",
+		NewValidation("smile", ""):                         "Smile :}",
+		NewValidation("count", "").WithArg("n", 1500):      "1,500 items",
+	}
+	for e, want := range cases {
+		if got := r.Resolve(context.Background(), e); got != want {
+			t.Errorf("%s: got %q, want %q", e.Code, got, want)
+		}
+	}
+}
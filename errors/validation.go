@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"context"
+	"strings"
+)
+
+// CodeValidationFailed is the Code a ValidationErrors reports as a whole, e.g. through
+// CodeOf or as the problem+json type. Add it to the dictionary to localize the title.
+const CodeValidationFailed = "validation.failed"
+
+// FieldError is a failure on one input field. Field is the path as the client sent
+// it, e.g. "email" or "items[0].qty".
+type FieldError struct {
+	Field string
+	Err   *Error
+}
+
+// ValidationErrors collects the field errors of one request so the client sees every
+// failure at once, not just the first:
+//
+//	var verrs errors.ValidationErrors
+//	if req.Email == "" {
+//	    verrs.AddNew("email", "email.required", "Email is required")
+//	}
+//	if len(req.Password) < 8 {
+//	    verrs.AddNew("password", "password.too_short", "Too short").WithArg("min", 8)
+//	}
+//	return verrs.Err()
+//
+// As a whole it is a KindValidation *Error with Code CodeValidationFailed, so
+// StatusCode maps it to 400 (and grpcerrors.Code to InvalidArgument). errors.Is
+// matches any of the field errors by Code; WriteProblem lists them under "errors".
+type ValidationErrors []FieldError
+
+// Add records e against field. Nil errors are ignored.
+func (v *ValidationErrors) Add(field string, e *Error) {
+	if e == nil {
+		return
+	}
+	*v = append(*v, FieldError{Field: field, Err: e})
+}
+
+// AddNew records a new KindValidation error against field and returns it, so args can
+// be chained with WithArg.
+func (v *ValidationErrors) AddNew(field, code, message string) *Error {
+	e := NewValidation(code, message)
+	v.Add(field, e)
+	return e
+}
+
+// Err returns v as an error, or nil when nothing was recorded. Return this rather than
+// v itself so an empty collection does not become a non-nil error.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error lists every field error using their default messages.
+func (v ValidationErrors) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed")
+	for i, fe := range v {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(fe.Field)
+		b.WriteString(": ")
+		b.WriteString(fe.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the field errors for errors.Is / errors.As.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, fe := range v {
+		errs[i] = fe.Err
+	}
+	return errs
+}
+
+// As presents v as the aggregate *Error, so KindOf, CodeOf, StatusCode and Resolve
+// describe the request as a whole rather than its first field.
+func (v ValidationErrors) As(target any) bool {
+	t, ok := target.(**Error)
+	if !ok {
+		return false
+	}
+	*t = &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: "Validation failed"}
+	return true
+}
+
+// Messages resolves every field error through the default resolver for the locale in
+// ctx. A field with several errors gets the first one recorded.
+func (v ValidationErrors) Messages(ctx context.Context) map[string]string {
+	out := make(map[string]string, len(v))
+	for _, fe := range v {
+		if _, ok := out[fe.Field]; !ok {
+			out[fe.Field] = Resolve(ctx, fe.Err)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidationErrorsAggregate(t *testing.T) {
+	var verrs ValidationErrors
+	if verrs.Err() != nil {
+		t.Fatal("an empty collection must not be an error")
+	}
+	tooShort := verrs.AddNew("password", "password.too_short", "Too short").WithArg("min", 8)
+	verrs.Add("email", NewValidation("email.invalid", "Invalid email"))
+	verrs.Add("ignored", nil)
+
+	err := fmt.Errorf("signup: %w", verrs.Err())
+	if StatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("unexpected transport mapping: %d", StatusCode(err))
+	}
+	if KindOf(err) != KindValidation || CodeOf(err) != CodeValidationFailed {
+		t.Fatalf("aggregate must report itself, got %v %q", KindOf(err), CodeOf(err))
+	}
+	if !stderrors.Is(err, NewValidation("email.invalid", "")) || !stderrors.Is(err, tooShort) {
+		t.Fatal("errors.Is must match field errors by Code")
+	}
+	if stderrors.Is(err, NewValidation("name.required", "")) {
+		t.Fatal("errors.Is must not match an unrecorded Code")
+	}
+	if len(verrs.Unwrap()) != 2 {
+		t.Fatalf("expected 2 field errors, got %d", len(verrs.Unwrap()))
+	}
+	want := "validation failed: password: [password.too_short] Too short; email: [email.invalid] Invalid email"
+	if verrs.Error() != want {
+		t.Fatalf("Error() = %q", verrs.Error())
+	}
+}
+
+func TestValidationErrorsResolvePerLocale(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), `
+validation.failed: "Please fix the highlighted fields"
+password.too_short: "Use at least {{.min}} characters"
+`)
+	writeFile(t, filepath.Join(dir, "id.yaml"), `
+validation.failed: "Periksa kembali isian Anda"
+password.too_short: "Gunakan minimal {{.min}} karakter"
+`)
+	res, err := NewFileResolver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+	SetDefaultResolver(res)
+	defer SetDefaultResolver(nil)
+
+	var verrs ValidationErrors
+	verrs.AddNew("password", "password.too_short", "Too short").WithArg("min", 8)
+	verrs.AddNew("password", "password.weak", "Too weak")
+	verrs.AddNew("email", "email.invalid", "Invalid email")
+
+	ctx := ContextWithLocale(context.Background(), "id")
+	msgs := verrs.Messages(ctx)
+	if msgs["password"] != "Gunakan minimal 8 karakter" || msgs["email"] != "Invalid email" || len(msgs) != 2 {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/signup", nil).WithContext(ctx)
+	p := NewProblem(req, verrs.Err(), WithFieldErrors(map[string]error{"name": NewValidation("name.required", "Name is required")}))
+	if p.Status != 400 || p.Code != CodeValidationFailed || p.Title != "Periksa kembali isian Anda" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+	if len(p.Errors) != 4 || p.Errors[0].Detail != "Gunakan minimal 8 karakter" || p.Errors[1].Code != "password.weak" ||
+		p.Errors[2].Field != "email" || p.Errors[3].Field != "name" {
+		t.Fatalf("unexpected field errors: %+v", p.Errors)
+	}
+}